@@ -0,0 +1,42 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// bellFlashDuration is the time in milliseconds the screen flashes for a bell
+const bellFlashDuration = 100
+
+// bell rings the bell in the style, which is "flash", "sound" or "none"
+func (w *Workspace) bell(style string) {
+	switch style {
+	case "sound":
+		widgets.QApplication_Beep()
+	case "flash":
+		w.flashScreen()
+	}
+}
+
+// flashScreen covers the screen with a translucent layer for a moment
+func (w *Workspace) flashScreen() {
+	if w.bellFlash == nil {
+		w.bellFlash = widgets.NewQWidget(w.screen.widget, 0)
+		w.bellFlash.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+		w.bellFlash.Hide()
+		w.bellTimer = core.NewQTimer(nil)
+		w.bellTimer.SetSingleShot(true)
+		w.bellTimer.ConnectTimeout(w.bellFlash.Hide)
+	}
+	fg := w.foreground
+	w.bellFlash.SetStyleSheet(fmt.Sprintf(
+		" * { background-color: rgba(%d, %d, %d, 0.15); }",
+		fg.R, fg.G, fg.B,
+	))
+	w.bellFlash.SetGeometry(w.screen.widget.Rect())
+	w.bellFlash.Show()
+	w.bellFlash.Raise()
+	w.bellTimer.Start(bellFlashDuration)
+}
@@ -15,6 +15,12 @@ type CmdContent struct {
 	content string
 }
 
+// cmdlineChunk is a part of the cmdline content drawn with a highlight
+type cmdlineChunk struct {
+	hl   *Highlight
+	text string
+}
+
 // Cmdline is the cmdline
 type Cmdline struct {
 	shown         bool
@@ -27,6 +33,11 @@ type Cmdline struct {
 	rawItems      []interface{}
 	wildmenuShown bool
 	top           int
+	block         []string
+	chunks        []cmdlineChunk
+	// isPlain is set when every chunk has the default highlight, and the
+	// cmdline is drawn as plain text, which can stick out to the left
+	isPlain bool
 }
 
 func initCmdline() *Cmdline {
@@ -44,6 +55,8 @@ func (c *CmdContent) getText() string {
 	return fmt.Sprintf("%s%s", indentStr, c.content)
 }
 
+// getText returns the HTML of the cmdline, or its text if it is plain, with ch
+// inserted at the cursor position
 func (c *Cmdline) getText(ch string) string {
 	indentStr := ""
 	for i := 0; i < c.content.indent; i++ {
@@ -55,10 +68,33 @@ func (c *Cmdline) getText(ch string) string {
 	if len(c.content.content) == 0 {
 		c.pos = 0
 	}
-	return fmt.Sprintf("%s%s%s", c.content.firstc, indentStr, c.content.content[:c.pos]+ch+c.content.content[c.pos:])
+	if c.isPlain {
+		return fmt.Sprintf("%s%s%s", c.content.firstc, indentStr, c.content.content[:c.pos]+ch+c.content.content[c.pos:])
+	}
+
+	// The leading span makes the label take the text as rich text
+	text := fmt.Sprintf("<span>%s</span>", sanitize(c.content.firstc+indentStr))
+	offset := 0
+	isInserted := ch == ""
+	for _, chunk := range c.chunks {
+		chunkText := chunk.text
+		if !isInserted && c.pos <= offset+len(chunkText) {
+			i := c.pos - offset
+			chunkText = chunkText[:i] + ch + chunkText[i:]
+			isInserted = true
+		}
+		offset += len(chunk.text)
+		text += chunk.hl.toHTML(sanitize(chunkText), c.ws.background)
+	}
+	if !isInserted {
+		text += sanitize(ch)
+	}
+
+	return text
 }
 
 func sanitize(s string) string {
+	s = strings.Replace(s, "&", `&amp;`, -1)
 	s = strings.Replace(s, " ", `&nbsp;`, -1)
 	s = strings.Replace(s, "\t", `&nbsp;`, -1)
 	s = strings.Replace(s, "<", `&lt;`, -1)
@@ -74,37 +110,32 @@ func (c *Cmdline) show(args []interface{}) {
 	palette := c.ws.palette
 	arg := args[0].([]interface{})
 
+	// content is the plain text, which the cursor position refers to
 	content := ""
+	c.chunks = []cmdlineChunk{}
+	c.isPlain = true
 	contentChunks := arg[0].([]interface{})
 	for _, e := range contentChunks {
 		a := e.([]interface{})
 
+		chunk := cmdlineChunk{}
 		if len(a) < 2 {
-			// content += a[0].(string)
-			content += strings.Replace(a[0].(string), "\t", " ", -1)
+			chunk.hl = c.ws.screen.attrHighlight(0)
+			chunk.text = strings.Replace(a[0].(string), "\t", " ", -1)
 		} else {
-			color := c.ws.foreground
-			_, ok := c.ws.screen.hlAttrDef[util.ReflectToInt(a[0])]
-			if ok {
-				color = c.ws.screen.hlAttrDef[util.ReflectToInt(a[0])].foreground
-			}
-
-			// I don't know how to set sticking out direction of
-			// the contents of a qlabel with html text to the left.
-			if len(contentChunks) == 1 {
-				// content += a[1].(string)
-				content += strings.Replace(a[1].(string), "\t", " ", -1)
-			} else {
-				content += fmt.Sprintf(
-					"<font color='%s'>%s</font>",
-					color.Hex(),
-					sanitize(a[1].(string)),
-				)
-				palette.isHTMLText = true
+			id := util.ReflectToInt(a[0])
+			chunk.hl = c.ws.screen.attrHighlight(id)
+			chunk.text = strings.Replace(a[1].(string), "\t", " ", -1)
+			if id != 0 {
+				c.isPlain = false
 			}
 		}
+		c.chunks = append(c.chunks, chunk)
+		content += chunk.text
 	}
-	// content := arg[0].([]interface{})[0].([]interface{})[1].(string)
+	// I don't know how to set sticking out direction of the contents of a
+	// qlabel with html text to the left, so that plain text is kept plain.
+	palette.isHTMLText = !c.isPlain
 
 	pos := util.ReflectToInt(arg[1])
 	firstc := arg[2].(string)
@@ -214,6 +245,48 @@ func (c *Cmdline) hide() {
 	c.shown = false
 }
 
+// blockShow shows the lines entered so far in a multi-line command, such as
+// :function or :lua << EOF, above the cmdline
+func (c *Cmdline) blockShow(args []interface{}) {
+	if c.ws.palette == nil {
+		return
+	}
+	c.block = []string{}
+	for _, arg := range args {
+		lines, ok := arg.([]interface{})[0].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, line := range lines {
+			chunks, _ := line.([]interface{})
+			c.block = append(c.block, c.ws.screen.chunksHTML(chunks))
+		}
+	}
+	c.ws.palette.setBlock(c.block)
+}
+
+func (c *Cmdline) blockAppend(args []interface{}) {
+	if c.ws.palette == nil {
+		return
+	}
+	for _, arg := range args {
+		chunks, ok := arg.([]interface{})[0].([]interface{})
+		if !ok {
+			continue
+		}
+		c.block = append(c.block, c.ws.screen.chunksHTML(chunks))
+	}
+	c.ws.palette.setBlock(c.block)
+}
+
+func (c *Cmdline) blockHide() {
+	if c.ws.palette == nil {
+		return
+	}
+	c.block = nil
+	c.ws.palette.setBlock(nil)
+}
+
 func (c *Cmdline) functionShow() {
 	c.inFunction = true
 	c.function = []*CmdContent{c.preContent}
@@ -10,19 +10,22 @@ import (
 )
 
 type gonvimConfig struct {
-	Editor      editorConfig
-	Palette     paletteConfig
-	Message     messageConfig
-	Statusline  statusLineConfig
-	Tabline     tabLineConfig
-	Lint        lintConfig
-	Popupmenu   popupMenuConfig
-	ScrollBar   scrollBarConfig
-	MiniMap     miniMapConfig
-	Markdown    markdownConfig
-	SideBar     sideBarConfig
-	Workspace   workspaceConfig
-	FileExplore fileExploreConfig
+	Editor       editorConfig
+	Palette      paletteConfig
+	Message      messageConfig
+	MessageLog   messageLogConfig
+	Notification notificationConfig
+	Menu         menuConfig
+	Statusline   statusLineConfig
+	Tabline      tabLineConfig
+	Lint         lintConfig
+	Popupmenu    popupMenuConfig
+	ScrollBar    scrollBarConfig
+	MiniMap      miniMapConfig
+	Markdown     markdownConfig
+	SideBar      sideBarConfig
+	Workspace    workspaceConfig
+	FileExplore  fileExploreConfig
 }
 
 type editorConfig struct {
@@ -65,6 +68,7 @@ type editorConfig struct {
 	DiffChangePattern        int
 	ClickEffect              bool
 	BorderlessWindow         bool
+	Bell                     string
 	// ExtWildmenu            bool
 	// ExtMultigrid           bool
 }
@@ -79,6 +83,37 @@ type messageConfig struct {
 	Transparent float64
 }
 
+type messageLogConfig struct {
+	Visible    bool
+	Width      int
+	MaxEntries int
+	Persist    bool
+}
+
+type notificationConfig struct {
+	OverrideVimNotify bool
+	MaxStack          int
+	DoNotDisturb      bool
+	// MuteLevels are the levels of notifications which are only kept in the
+	// notification center without popping up
+	MuteLevels []string
+	// Desktop sends notifications to the desktop while the window is not
+	// focused. DesktopMessageKinds are the kinds of messages, where "*" is
+	// every kind, and DesktopLevels are the levels of notifications, sent to it.
+	// It is off by default.
+	Desktop             bool
+	DesktopMessageKinds []string
+	DesktopLevels       []string
+}
+
+type menuConfig struct {
+	Visible bool
+	// AutoHide hides the menu bar until Alt is pressed
+	AutoHide bool
+	// ContextMenu shows the PopUp menu on right click
+	ContextMenu bool
+}
+
 type statusLineConfig struct {
 	Visible           bool
 	ModeIndicatorType string
@@ -93,8 +128,11 @@ type statusLineConfig struct {
 }
 
 type tabLineConfig struct {
-	Visible  bool
-	ShowIcon bool
+	Visible       bool
+	ShowIcon      bool
+	ShowThumbnail bool
+	// Mode is "tabs" to list tabpages, or "buffers" to list the listed buffers
+	Mode string
 }
 
 type popupMenuConfig struct {
@@ -111,26 +149,51 @@ type lintConfig struct {
 }
 
 type miniMapConfig struct {
-	Visible bool
-	Disable bool
-	Width   int
+	Visible       bool
+	Disable       bool
+	Width         int
+	Diagnostics   bool
+	SearchMatches bool
+	GitChanges    bool
 }
 
 type markdownConfig struct {
-	Disable             bool
-	CodeHlStyle         string
-	CodeWithLineNumbers bool
+	Disable              bool
+	CodeHlStyle          string
+	CodeWithLineNumbers  bool
+	SyncScroll           bool
+	HighlightSyncedBlock bool
+	GFM                  bool
+	Footnote             bool
+	DefinitionList       bool
+	HideFrontMatter      bool
+	// Math renders TeX math with KaTeX, which is not bundled. Set
+	// MathRendererPath to the directory of the KaTeX distribution, which
+	// holds katex.min.css, katex.min.js, fonts/ and
+	// contrib/auto-render.min.js. It defaults to runtime/katex of goneovim.
+	Math             bool
+	MathRendererPath string
+	// Previewers maps filetypes to commands which convert the buffer
+	// from stdin to HTML or SVG on stdout, e.g. rst = "rst2html5"
+	Previewers map[string]string
 }
 
 type scrollBarConfig struct {
-	Visible bool
+	Visible       bool
+	Diagnostics   bool
+	SearchMatches bool
+	GitChanges    bool
+	CursorLine    bool
+	// PerWindow shows an overlay scrollbar on every window
+	PerWindow bool
 }
 
 type sideBarConfig struct {
-	Visible     bool
-	DropShadow  bool
-	Width       int
-	AccentColor string
+	Visible       bool
+	DropShadow    bool
+	Width         int
+	AccentColor   string
+	ShowThumbnail bool
 }
 
 type workspaceConfig struct {
@@ -208,6 +271,20 @@ func newConfig(home string) (string, gonvimConfig) {
 	if config.Statusline.ModeIndicatorType == "" {
 		config.Statusline.ModeIndicatorType = "textLabel"
 	}
+	switch config.Editor.Bell {
+	case "flash", "sound", "none":
+	default:
+		config.Editor.Bell = "flash"
+	}
+	if config.Tabline.Mode != "buffers" {
+		config.Tabline.Mode = "tabs"
+	}
+	if config.MessageLog.MaxEntries <= 0 {
+		config.MessageLog.MaxEntries = 1000
+	}
+	if config.Notification.MaxStack <= 0 {
+		config.Notification.MaxStack = 5
+	}
 
 	if config.Editor.FontFamily == "" {
 		switch runtime.GOOS {
@@ -324,6 +401,9 @@ func (c *gonvimConfig) init() {
 	c.Editor.DesktopNotifications = false
 	c.Editor.ClickEffect = false
 
+	// "flash", "sound" or "none"
+	c.Editor.Bell = "flash"
+
 	// replace diff color drawing pattern
 	c.Editor.DiffAddPattern = 1
 	c.Editor.DiffDeletePattern = 1
@@ -342,6 +422,29 @@ func (c *gonvimConfig) init() {
 
 	// ----
 
+	c.MessageLog.Visible = false
+	c.MessageLog.Width = 400
+	c.MessageLog.MaxEntries = 1000
+	c.MessageLog.Persist = false
+
+	// ----
+
+	c.Notification.OverrideVimNotify = false
+	c.Notification.MaxStack = 5
+	c.Notification.DoNotDisturb = false
+	c.Notification.MuteLevels = []string{"debug"}
+	c.Notification.Desktop = false
+	c.Notification.DesktopMessageKinds = []string{"emsg", "echoerr", "lua_error", "rpc_error"}
+	c.Notification.DesktopLevels = []string{"error", "warn"}
+
+	// ----
+
+	c.Menu.Visible = true
+	c.Menu.AutoHide = false
+	c.Menu.ContextMenu = true
+
+	// ----
+
 	c.Statusline.Visible = false
 	c.Statusline.ModeIndicatorType = "textLabel"
 	c.Statusline.Left = []string{"mode", "filepath", "filename"}
@@ -351,6 +454,8 @@ func (c *gonvimConfig) init() {
 
 	c.Tabline.Visible = true
 	c.Tabline.ShowIcon = true
+	c.Tabline.ShowThumbnail = true
+	c.Tabline.Mode = "tabs"
 
 	// ----
 
@@ -367,21 +472,37 @@ func (c *gonvimConfig) init() {
 	// ----
 
 	c.ScrollBar.Visible = false
+	c.ScrollBar.Diagnostics = true
+	c.ScrollBar.SearchMatches = true
+	c.ScrollBar.GitChanges = true
+	c.ScrollBar.CursorLine = true
+	c.ScrollBar.PerWindow = false
 
 	// ----
 
 	c.MiniMap.Width = 110
+	c.MiniMap.Diagnostics = true
+	c.MiniMap.SearchMatches = true
+	c.MiniMap.GitChanges = true
 
 	// ----
 
 	c.Markdown.Disable = false
 	c.Markdown.CodeHlStyle = "github"
+	c.Markdown.SyncScroll = true
+	c.Markdown.HighlightSyncedBlock = false
+	c.Markdown.GFM = true
+	c.Markdown.Footnote = true
+	c.Markdown.DefinitionList = true
+	c.Markdown.HideFrontMatter = true
+	c.Markdown.Math = false
 
 	// ----
 
 	c.SideBar.Visible = false
 	c.SideBar.Width = 200
 	c.SideBar.AccentColor = "#5596ea"
+	c.SideBar.ShowThumbnail = true
 
 	// ----
 
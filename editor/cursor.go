@@ -34,6 +34,7 @@ type Cursor struct {
 	font             *Font
 	fontwide         *Font
 	isInPalette      bool
+	isBusy           bool
 	charCache        *Cache
 	devicePixelRatio float64
 
@@ -62,6 +63,10 @@ func initCursorNew() *Cursor {
 }
 
 func (c *Cursor) paint(event *gui.QPaintEvent) {
+	// The cursor is not drawn while nvim is busy
+	if c.isBusy {
+		return
+	}
 	font := c.font
 	if font == nil {
 		return
@@ -254,6 +259,14 @@ func (c *Cursor) move() {
 	}
 }
 
+func (c *Cursor) setBusy(busy bool) {
+	if c.isBusy == busy {
+		return
+	}
+	c.isBusy = busy
+	c.widget.Update()
+}
+
 func (c *Cursor) updateFont(font *Font) {
 	c.font = font
 }
@@ -0,0 +1,133 @@
+package editor
+
+import (
+	"errors"
+
+	"github.com/therecipe/qt/widgets"
+)
+
+// desktopNotifier sends notifications to the desktop
+type desktopNotifier interface {
+	notify(n *desktopNotification) error
+}
+
+// desktopNotification is a notification sent to the desktop. The buttons are
+// shown as the actions of the notification where the desktop supports them.
+type desktopNotification struct {
+	title   string
+	body    string
+	level   NotifyLevel
+	buttons []*NotifyButton
+}
+
+var errNoPlatformNotifier = errors.New("no desktop notification service")
+
+// trayNotifier shows notifications as the balloon messages of the system tray
+type trayNotifier struct {
+	tray *widgets.QSystemTrayIcon
+}
+
+func (t *trayNotifier) notify(n *desktopNotification) error {
+	icon := widgets.QSystemTrayIcon__Information
+	switch n.level {
+	case NotifyError:
+		icon = widgets.QSystemTrayIcon__Critical
+	case NotifyWarn:
+		icon = widgets.QSystemTrayIcon__Warning
+	}
+	t.tray.ShowMessage(n.title, n.body, icon, 5000)
+
+	return nil
+}
+
+// messageKindLevel returns the notification level of the msg_show kind
+func messageKindLevel(kind string) NotifyLevel {
+	switch kind {
+	case "emsg", "echoerr", "lua_error", "rpc_error":
+		return NotifyError
+	case "wmsg":
+		return NotifyWarn
+	}
+
+	return NotifyInfo
+}
+
+// hasMessageKind reports whether the kind is in the kinds of the config,
+// where "*" matches every kind
+func hasMessageKind(kind string, kinds []string) bool {
+	for _, k := range kinds {
+		if k == "*" || k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// platformNotifier returns the notification service of the desktop, or nil
+// if there is none
+func (e *Editor) platformNotifier() desktopNotifier {
+	e.desktopOnce.Do(func() {
+		n, err := newPlatformNotifier()
+		if err != nil {
+			e.putLog("use the system tray for desktop notifications:", err)
+			return
+		}
+		e.desktop = n
+	})
+
+	return e.desktop
+}
+
+// canNotifyDesktop reports whether notifications should be escalated to the
+// desktop, which is done while the window is not focused
+func (e *Editor) canNotifyDesktop() bool {
+	return e.config.Notification.Desktop && !e.doNotDisturb && !e.window.IsActiveWindow()
+}
+
+// notifyDesktop sends the notification off the UI thread, as connecting to
+// the session bus and calling the service may block. The system tray is used
+// on the UI thread if the desktop has no notification service.
+func (e *Editor) notifyDesktop(n *desktopNotification) {
+	if n.title == "" {
+		n.title = "Goneovim"
+	}
+	go func() {
+		notifier := e.platformNotifier()
+		if notifier == nil {
+			e.trayNotifications <- n
+			e.signal.TrayNotifySignal()
+			return
+		}
+		if err := notifier.notify(n); err != nil {
+			e.putLog("failed to send a desktop notification:", err)
+		}
+	}()
+}
+
+// escalateMessage sends the message of msg_show to the desktop, and reports
+// whether it has been sent
+func (e *Editor) escalateMessage(kind, text string) bool {
+	if !e.canNotifyDesktop() || !hasMessageKind(kind, e.config.Notification.DesktopMessageKinds) {
+		return false
+	}
+	e.notifyDesktop(&desktopNotification{
+		body:  text,
+		level: messageKindLevel(kind),
+	})
+
+	return true
+}
+
+// escalateNotification sends the goneovim notification to the desktop as well
+func (e *Editor) escalateNotification(notify *Notify) {
+	if !e.canNotifyDesktop() || !hasNotifyLevel(notify.level, e.config.Notification.DesktopLevels) {
+		return
+	}
+	e.notifyDesktop(&desktopNotification{
+		title:   notify.title,
+		body:    notify.message,
+		level:   notify.level,
+		buttons: notify.buttons,
+	})
+}
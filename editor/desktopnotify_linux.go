@@ -0,0 +1,153 @@
+// +build linux
+
+package editor
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notificationsName = "org.freedesktop.Notifications"
+	notificationsPath = dbus.ObjectPath("/org/freedesktop/Notifications")
+)
+
+// freedesktopNotifier sends notifications with the freedesktop notification
+// D-Bus interface
+type freedesktopNotifier struct {
+	conn *dbus.Conn
+
+	mu      sync.Mutex
+	buttons map[uint32][]*NotifyButton
+}
+
+func newPlatformNotifier() (desktopNotifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	n, err := newFreedesktopNotifier(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// newFreedesktopNotifier returns the notifier on the bus connection. It fails
+// if no notification service is running on the bus.
+func newFreedesktopNotifier(conn *dbus.Conn) (*freedesktopNotifier, error) {
+	var hasOwner bool
+	err := conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, notificationsName).Store(&hasOwner)
+	if err != nil {
+		return nil, err
+	}
+	if !hasOwner {
+		return nil, errNoPlatformNotifier
+	}
+
+	err = conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(notificationsPath),
+		dbus.WithMatchInterface(notificationsName),
+	)
+	if err != nil {
+		return nil, err
+	}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	n := &freedesktopNotifier{
+		conn:    conn,
+		buttons: make(map[uint32][]*NotifyButton),
+	}
+	go n.handleSignals(signals)
+
+	return n, nil
+}
+
+// freedesktopUrgency returns the urgency hint of the level
+func freedesktopUrgency(level NotifyLevel) byte {
+	switch level {
+	case NotifyError:
+		return 2
+	case NotifyDebug:
+		return 0
+	}
+
+	return 1
+}
+
+func (n *freedesktopNotifier) notify(d *desktopNotification) error {
+	// The keys of the actions are the indexes of the buttons
+	actions := []string{}
+	for i, b := range d.buttons {
+		actions = append(actions, strconv.Itoa(i), b.text)
+	}
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(freedesktopUrgency(d.level)),
+	}
+
+	var id uint32
+	err := n.conn.Object(notificationsName, notificationsPath).Call(
+		notificationsName+".Notify", 0,
+		"goneovim",
+		uint32(0),
+		"",
+		d.title,
+		d.body,
+		actions,
+		hints,
+		int32(-1),
+	).Store(&id)
+	if err != nil {
+		return err
+	}
+	if len(d.buttons) > 0 {
+		n.mu.Lock()
+		n.buttons[id] = d.buttons
+		n.mu.Unlock()
+	}
+
+	return nil
+}
+
+// handleSignals runs the action of the button invoked on the desktop
+func (n *freedesktopNotifier) handleSignals(signals chan *dbus.Signal) {
+	for s := range signals {
+		if len(s.Body) < 1 {
+			continue
+		}
+		id, ok := s.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+
+		n.mu.Lock()
+		buttons := n.buttons[id]
+		switch s.Name {
+		case notificationsName + ".ActionInvoked", notificationsName + ".NotificationClosed":
+			delete(n.buttons, id)
+		}
+		n.mu.Unlock()
+
+		if s.Name != notificationsName+".ActionInvoked" || len(s.Body) < 2 {
+			continue
+		}
+		key, _ := s.Body[1].(string)
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(buttons) {
+			continue
+		}
+		b := buttons[i]
+		if b.clicked == nil {
+			go b.action()
+			continue
+		}
+		// The button is marked clicked on the UI thread first
+		editor.desktopClicks <- b
+		editor.signal.DesktopClickSignal()
+	}
+}
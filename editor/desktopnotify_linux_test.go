@@ -0,0 +1,142 @@
+// +build linux
+
+package editor
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// standInNotifications records the calls of the freedesktop notification
+// interface in place of a notification daemon
+type standInNotifications struct {
+	conn *dbus.Conn
+
+	mu    sync.Mutex
+	calls []standInNotify
+}
+
+type standInNotify struct {
+	summary string
+	body    string
+	actions []string
+	urgency byte
+}
+
+func (s *standInNotifications) Notify(app string, replaces uint32, icon, summary, body string, actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var urgency byte
+	if v, ok := hints["urgency"]; ok {
+		urgency, _ = v.Value().(byte)
+	}
+	s.calls = append(s.calls, standInNotify{summary, body, actions, urgency})
+
+	return uint32(len(s.calls)), nil
+}
+
+// startSessionBus starts a private session bus, and returns its address
+func startSessionBus(t *testing.T) string {
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+	cmd := exec.Command(path, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skip("failed to start dbus-daemon:", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.TrimSpace(address)
+}
+
+func connectBus(t *testing.T, address string) *dbus.Conn {
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	return conn
+}
+
+func TestFreedesktopNotifier(t *testing.T) {
+	address := startSessionBus(t)
+
+	if _, err := newFreedesktopNotifier(connectBus(t, address)); err != errNoPlatformNotifier {
+		t.Fatalf("newFreedesktopNotifier() without a service, error = %v, want %v", err, errNoPlatformNotifier)
+	}
+
+	standIn := &standInNotifications{conn: connectBus(t, address)}
+	if err := standIn.conn.Export(standIn, notificationsPath, notificationsName); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := standIn.conn.RequestName(notificationsName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own %s: %v", notificationsName, err)
+	}
+
+	notifier, err := newFreedesktopNotifier(connectBus(t, address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clicked := make(chan string, 1)
+	err = notifier.notify(&desktopNotification{
+		title: "Goneovim",
+		body:  "reload file?",
+		level: NotifyError,
+		buttons: []*NotifyButton{
+			{text: "Reload", action: func() { clicked <- "Reload" }},
+			{text: "Ignore", action: func() { clicked <- "Ignore" }},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	standIn.mu.Lock()
+	calls := standIn.calls
+	standIn.mu.Unlock()
+	if len(calls) != 1 {
+		t.Fatalf("Notify is called %d times, want 1", len(calls))
+	}
+	got := calls[0]
+	want := standInNotify{"Goneovim", "reload file?", []string{"0", "Reload", "1", "Ignore"}, 2}
+	if got.summary != want.summary || got.body != want.body || strings.Join(got.actions, ",") != strings.Join(want.actions, ",") || got.urgency != want.urgency {
+		t.Errorf("Notify is called with %+v, want %+v", got, want)
+	}
+
+	// The stand-in reports that the user invoked the second action
+	err = standIn.conn.Emit(notificationsPath, notificationsName+".ActionInvoked", uint32(1), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case button := <-clicked:
+		if button != "Ignore" {
+			t.Errorf("clicked button = %s, want Ignore", button)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the action of the invoked button is not run")
+	}
+}
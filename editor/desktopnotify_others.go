@@ -0,0 +1,8 @@
+// +build !linux
+
+package editor
+
+// newPlatformNotifier returns no notifier, so that the system tray is used
+func newPlatformNotifier() (desktopNotifier, error) {
+	return nil, errNoPlatformNotifier
+}
@@ -0,0 +1,26 @@
+package editor
+
+import "testing"
+
+func TestHasMessageKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  string
+		kinds []string
+		want  bool
+	}{
+		{"included", "emsg", []string{"emsg", "wmsg"}, true},
+		{"not included", "echo", []string{"emsg", "wmsg"}, false},
+		{"every kind", "lua_print", []string{"*"}, true},
+		{"empty kind", "", []string{"*"}, true},
+		{"no kinds", "emsg", nil, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasMessageKind(tt.kind, tt.kinds); got != tt.want {
+				t.Errorf("hasMessageKind(%q, %v) = %v, want %v", tt.kind, tt.kinds, got, tt.want)
+			}
+		})
+	}
+}
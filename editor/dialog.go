@@ -0,0 +1,216 @@
+package editor
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Dialog shows the prompts of confirm(), :s///c and the hit-enter prompt
+// with buttons. The keyboard focus stays in the editor, so the keys can still
+// be typed while the dialog is shown.
+type Dialog struct {
+	ws      *Workspace
+	widget  *widgets.QWidget
+	label   *widgets.QLabel
+	buttons *widgets.QWidget
+}
+
+type dialogButton struct {
+	text string
+	key  string
+}
+
+var confirmSubLabels = map[string]string{
+	"y":  "Yes",
+	"n":  "No",
+	"a":  "All",
+	"q":  "Quit",
+	"l":  "Last",
+	"^E": "Scroll Up",
+	"^Y": "Scroll Down",
+}
+
+func isDialogKind(kind string) bool {
+	switch kind {
+	case "confirm", "confirm_sub", "return_prompt":
+		return true
+	}
+
+	return false
+}
+
+// parseDialog splits the prompt of the kind into the message and the buttons
+// made from its choices. It returns no buttons if the choices are unknown.
+func parseDialog(kind, text string) (string, []*dialogButton) {
+	text = strings.TrimSpace(text)
+
+	switch kind {
+	case "return_prompt":
+		return text, []*dialogButton{{"Continue", "<CR>"}}
+	case "confirm_sub":
+		start := strings.LastIndex(text, "(")
+		end := strings.LastIndex(text, ")")
+		if start < 0 || end < start {
+			return text, nil
+		}
+		buttons := []*dialogButton{}
+		for _, k := range strings.Split(text[start+1:end], "/") {
+			label, ok := confirmSubLabels[k]
+			if !ok {
+				label = k
+			}
+			key := k
+			if len(k) == 2 && k[0] == '^' {
+				key = "<C-" + k[1:] + ">"
+			}
+			buttons = append(buttons, &dialogButton{label, key})
+		}
+		return strings.TrimSpace(text[:start]), buttons
+	}
+
+	message, choices := "", text
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		message, choices = strings.TrimSpace(text[:i]), text[i+1:]
+	}
+	choices = strings.TrimSuffix(strings.TrimSpace(choices), ":")
+	sep := ", "
+	if !strings.Contains(choices, sep) {
+		sep = "/"
+	}
+	buttons := []*dialogButton{}
+	for _, c := range strings.Split(choices, sep) {
+		label, key := choiceAccelerator(strings.TrimSpace(c))
+		if key == "" {
+			continue
+		}
+		buttons = append(buttons, &dialogButton{label, key})
+	}
+	if len(buttons) == 0 {
+		return text, nil
+	}
+
+	return message, buttons
+}
+
+// choiceAccelerator returns the label and the key of a choice like "&Yes",
+// "[Y]es" or "(N)o"
+func choiceAccelerator(choice string) (string, string) {
+	if i := strings.Index(choice, "&"); i >= 0 && i+1 < len(choice) {
+		_, size := utf8.DecodeRuneInString(choice[i+1:])
+		return choice[:i] + choice[i+1:], choice[i+1 : i+1+size]
+	}
+	for _, b := range []string{"[]", "()"} {
+		i := strings.Index(choice, b[:1])
+		j := strings.Index(choice, b[1:])
+		if i >= 0 && j > i+1 {
+			return choice[:i] + choice[i+1:j] + choice[j+1:], choice[i+1 : j]
+		}
+	}
+
+	return choice, ""
+}
+
+func initDialog(ws *Workspace) *Dialog {
+	widget := widgets.NewQWidget(ws.screen.widget, 0)
+	widget.SetFocusPolicy(core.Qt__NoFocus)
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(16, 14, 16, 14)
+	layout.SetSpacing(12)
+	widget.SetLayout(layout)
+
+	label := widgets.NewQLabel(nil, 0)
+	label.SetWordWrap(true)
+	label.SetTextFormat(core.Qt__RichText)
+	label.SetStyleSheet(" * {background-color: rgba(0, 0, 0, 0)}")
+
+	buttons := widgets.NewQWidget(nil, 0)
+	buttons.SetStyleSheet(" * {background-color: rgba(0, 0, 0, 0)}")
+
+	layout.AddWidget(label, 0, 0)
+	layout.AddWidget(buttons, 0, 0)
+	layout.SetAlignment(buttons, core.Qt__AlignRight)
+
+	go func() {
+		widget.SetGraphicsEffect(util.DropShadow(-2, -1, 40, 200))
+	}()
+	widget.Hide()
+
+	return &Dialog{
+		ws:      ws,
+		widget:  widget,
+		label:   label,
+		buttons: buttons,
+	}
+}
+
+// showDialog shows the prompt of the kind in the dialog, and reports whether
+// the prompt has choices to show as buttons
+func (w *Workspace) showDialog(kind, text string) bool {
+	message, buttons := parseDialog(kind, text)
+	if len(buttons) == 0 {
+		return false
+	}
+	if w.dialog == nil {
+		w.dialog = initDialog(w)
+	}
+	w.dialog.show(message, buttons)
+
+	return true
+}
+
+func (w *Workspace) hideDialog() {
+	if w.dialog == nil {
+		return
+	}
+	w.dialog.widget.Hide()
+}
+
+func (d *Dialog) show(message string, buttons []*dialogButton) {
+	layout := widgets.NewQHBoxLayout()
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(10)
+	buttonsWidget := widgets.NewQWidget(nil, 0)
+	buttonsWidget.SetStyleSheet(" * {background-color: rgba(0, 0, 0, 0)}")
+	buttonsWidget.SetLayout(layout)
+	for _, b := range buttons {
+		key := b.key
+		if key == "<" {
+			key = "<lt>"
+		}
+		layout.AddWidget(newNotifyButtonWidget(b.text, func() {
+			d.widget.Hide()
+			go d.ws.nvim.Input(key)
+		}), 0, 0)
+	}
+	d.widget.Layout().ReplaceWidget(d.buttons, buttonsWidget, core.Qt__FindDirectChildrenOnly)
+	d.buttons.DeleteLater()
+	d.buttons = buttonsWidget
+	d.widget.Layout().SetAlignment(d.buttons, core.Qt__AlignRight)
+
+	d.label.SetFont(gui.NewQFont2(editor.extFontFamily, editor.extFontSize, 1, false))
+	d.label.SetText(strings.Replace(html.EscapeString(message), "\n", "<br>", -1))
+	d.label.SetVisible(message != "")
+
+	bg := editor.colors.widgetBg
+	d.widget.SetStyleSheet(fmt.Sprintf(
+		" * {color: %s; background: rgba(%d, %d, %d, %f);}",
+		editor.colors.widgetFg.String(),
+		bg.R, bg.G, bg.B,
+		transparent(),
+	))
+	d.widget.SetMaximumWidth(d.ws.screen.widget.Width() * 2 / 3)
+	d.widget.AdjustSize()
+	d.widget.Move2(
+		(d.ws.screen.widget.Width()-d.widget.Width())/2,
+		(d.ws.screen.widget.Height()-d.widget.Height())/3,
+	)
+	d.widget.Show()
+	d.widget.Raise()
+}
@@ -0,0 +1,79 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDialog(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		text        string
+		wantMessage string
+		wantButtons []*dialogButton
+	}{
+		{
+			"confirm",
+			"confirm",
+			"Save changes to \"foo.txt\"?\n[Y]es, (N)o, (C)ancel: ",
+			"Save changes to \"foo.txt\"?",
+			[]*dialogButton{{"Yes", "Y"}, {"No", "N"}, {"Cancel", "C"}},
+		},
+		{
+			"confirm with accelerators inside the labels",
+			"confirm",
+			"\nOpen file?\n\n(O)pen, Sa(v)e, [Q]uit: ",
+			"Open file?",
+			[]*dialogButton{{"Open", "O"}, {"Save", "v"}, {"Quit", "Q"}},
+		},
+		{
+			"confirm with ampersands",
+			"confirm",
+			"Reload?\n&Yes/&No/&Cancel",
+			"Reload?",
+			[]*dialogButton{{"Yes", "Y"}, {"No", "N"}, {"Cancel", "C"}},
+		},
+		{
+			"confirm without choices",
+			"confirm",
+			"Continue?",
+			"Continue?",
+			nil,
+		},
+		{
+			"substitute",
+			"confirm_sub",
+			"replace with bar (y/n/a/q/l/^E/^Y)?",
+			"replace with bar",
+			[]*dialogButton{
+				{"Yes", "y"},
+				{"No", "n"},
+				{"All", "a"},
+				{"Quit", "q"},
+				{"Last", "l"},
+				{"Scroll Up", "<C-E>"},
+				{"Scroll Down", "<C-Y>"},
+			},
+		},
+		{
+			"hit-enter prompt",
+			"return_prompt",
+			"Press ENTER or type command to continue\n",
+			"Press ENTER or type command to continue",
+			[]*dialogButton{{"Continue", "<CR>"}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			message, buttons := parseDialog(tt.kind, tt.text)
+			if message != tt.wantMessage {
+				t.Errorf("parseDialog() message = %q, want %q", message, tt.wantMessage)
+			}
+			if !reflect.DeepEqual(buttons, tt.wantButtons) {
+				t.Errorf("parseDialog() buttons = %v, want %v", buttons, tt.wantButtons)
+			}
+		})
+	}
+}
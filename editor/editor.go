@@ -33,6 +33,9 @@ type editorSignal struct {
 	core.QObject
 	_ func() `signal:"notifySignal"`
 	_ func() `signal:"sidebarSignal"`
+	_ func() `signal:"trayNotifySignal"`
+	_ func() `signal:"desktopClickSignal"`
+	_ func() `signal:"workspaceScriptSignal"`
 }
 
 // ColorPalette is
@@ -63,6 +66,9 @@ type ColorPalette struct {
 type NotifyButton struct {
 	action func()
 	text   string
+	// clicked is called on the UI thread before action runs, and reports
+	// whether action may run
+	clicked func() bool
 }
 
 // Notify is
@@ -71,6 +77,9 @@ type Notify struct {
 	period  int
 	message string
 	buttons []*NotifyButton
+	id      string
+	title   string
+	dismiss func()
 }
 
 type Options struct {
@@ -110,9 +119,15 @@ type Editor struct {
 	splitter  *widgets.QSplitter
 	widget    *widgets.QWidget
 	side      *WorkspaceSide
+	msgLog    *MessageLog
 	sidetChan chan *widgets.QScrollArea
 
-	sysTray *widgets.QSystemTrayIcon
+	sysTray           *widgets.QSystemTrayIcon
+	desktop           desktopNotifier
+	desktopOnce       sync.Once
+	trayNotifications chan *desktopNotification
+	desktopClicks     chan *NotifyButton
+	workspaceScripts  chan string
 
 	width    int
 	height   int
@@ -127,12 +142,17 @@ type Editor struct {
 	keyControl         core.Qt__Key
 	keyCmd             core.Qt__Key
 	isKeyAutoRepeating bool
+	isAltPressedAlone  bool
 	prefixToMapMetaKey string
 	muMetaKey          sync.Mutex
 
 	config                 gonvimConfig
 	notifications          []*Notification
 	isDisplayNotifications bool
+	notifyHistory          []*notifyRecord
+	notifyCenter           *NotificationCenter
+	doNotDisturb           bool
+	thumbnail              *Thumbnail
 
 	isSetGuiColor bool
 	colors        *ColorPalette
@@ -196,6 +216,9 @@ func InitEditor(options Options, args []string) {
 	e.signal = NewEditorSignal(nil)
 	e.stop = make(chan struct{})
 	e.notify = make(chan *Notify, 10)
+	e.trayNotifications = make(chan *desktopNotification, 10)
+	e.desktopClicks = make(chan *NotifyButton, 10)
+	e.workspaceScripts = make(chan string, 10)
 	e.cbChan = make(chan *string, 240)
 
 	// detect home dir
@@ -278,6 +301,10 @@ func InitEditor(options Options, args []string) {
 	l.AddWidget(e.splitter, 1, 0)
 	e.putLog("window layout done")
 
+	e.signal.ConnectWorkspaceScriptSignal(func() {
+		e.workspaceNewWithScript(<-e.workspaceScripts)
+	})
+
 	e.font = <-fontGenAsync
 	e.putLog("done calculating the width of the font.")
 
@@ -416,6 +443,10 @@ func (e *Editor) initWorkspaces() {
 		e.workspaces = append(e.workspaces, ws)
 	}
 
+	if e.config.MessageLog.Visible {
+		e.toggleMessageLog()
+	}
+
 	e.workspaceUpdate()
 
 	e.widget.SetAttribute(core.Qt__WA_InputMethodEnabled, true)
@@ -468,15 +499,37 @@ func (e *Editor) initNotifications() {
 	e.notifications = []*Notification{}
 	e.notificationWidth = e.config.Editor.Width * 2 / 3
 	e.notifyStartPos = core.NewQPoint2(e.width-e.notificationWidth-10, e.height-30)
+	e.doNotDisturb = e.config.Notification.DoNotDisturb
 	e.signal.ConnectNotifySignal(func() {
 		notify := <-e.notify
 		if notify.message == "" {
 			return
 		}
-		if notify.buttons == nil {
-			e.popupNotification(notify.level, notify.period, notify.message)
-		} else {
-			e.popupNotification(notify.level, notify.period, notify.message, notifyOptionArg(notify.buttons))
+		popup := e.recordNotification(notify)
+		e.escalateNotification(notify)
+		if !popup {
+			return
+		}
+		opts := []NotifyOptionArg{
+			notifyIDOptionArg(notify.id),
+			notifyTitleOptionArg(notify.title),
+		}
+		if notify.buttons != nil {
+			opts = append(opts, notifyOptionArg(notify.buttons))
+		}
+		e.popupNotification(notify.level, notify.period, notify.message, opts...)
+	})
+	e.signal.ConnectTrayNotifySignal(func() {
+		n := <-e.trayNotifications
+		if e.sysTray == nil {
+			e.newSysTray()
+		}
+		(&trayNotifier{tray: e.sysTray}).notify(n)
+	})
+	e.signal.ConnectDesktopClickSignal(func() {
+		b := <-e.desktopClicks
+		if b.clicked() {
+			go b.action()
 		}
 	})
 }
@@ -485,6 +538,10 @@ func (e *Editor) initSysTray() {
 	if !e.config.Editor.DesktopNotifications {
 		return
 	}
+	e.newSysTray()
+}
+
+func (e *Editor) newSysTray() {
 	pixmap := gui.NewQPixmap()
 	color := ""
 	size := 0.95
@@ -561,12 +618,28 @@ func (e *Editor) pushNotification(level NotifyLevel, p int, message string, opt
 		period:  p,
 		message: message,
 		buttons: a.buttons,
+		id:      a.id,
+		title:   a.title,
+		dismiss: a.dismiss,
 	}
 	e.notify <- n
 	e.signal.NotifySignal()
 }
 
 func (e *Editor) popupNotification(level NotifyLevel, p int, message string, opt ...NotifyOptionArg) {
+	a := NotifyOptions{}
+	for _, o := range opt {
+		o(&a)
+	}
+	if a.id != "" {
+		for _, n := range e.notifications {
+			if n.id == a.id {
+				n.update(level, p, a.title, message, a.buttons)
+				return
+			}
+		}
+	}
+
 	e.updateNotificationPos()
 	notification := newNotification(level, p, message, opt...)
 	notification.widget.SetParent(e.window)
@@ -577,6 +650,21 @@ func (e *Editor) popupNotification(level NotifyLevel, p int, message string, opt
 	e.notifyStartPos = core.NewQPoint2(x, y)
 	e.notifications = append(e.notifications, notification)
 	notification.show()
+	e.limitNotifications()
+}
+
+// limitNotifications hides the oldest notifications so that no more than
+// Notification.MaxStack notifications are shown at once
+func (e *Editor) limitNotifications() {
+	shown := []*Notification{}
+	for _, n := range e.notifications {
+		if !n.isHide {
+			shown = append(shown, n)
+		}
+	}
+	for i := 0; i < len(shown)-e.config.Notification.MaxStack; i++ {
+		shown[i].hideNotification()
+	}
 }
 
 func (e *Editor) initColorPalette() {
@@ -739,11 +827,16 @@ func (e *Editor) copyClipBoard() {
 }
 
 func (e *Editor) workspaceNew() {
+	e.workspaceNewWithScript("")
+}
+
+// workspaceNewWithScript adds a workspace which sources the Vim script of path once nvim is attached
+func (e *Editor) workspaceNewWithScript(path string) {
 	if len(e.workspaces) == 10 {
 		return
 	}
 	editor.isSetGuiColor = false
-	ws, err := newWorkspace("")
+	ws, err := newWorkspace(path)
 	if err != nil {
 		return
 	}
@@ -781,6 +874,9 @@ func (e *Editor) workspacePrevious() {
 }
 
 func (e *Editor) workspaceUpdate() {
+	if e.msgLog != nil {
+		e.msgLog.refresh()
+	}
 	if e.side == nil {
 		return
 	}
@@ -788,6 +884,10 @@ func (e *Editor) workspaceUpdate() {
 		if i == e.active {
 			ws.show()
 		} else {
+			// Keep the last image of the workspace being left for its thumbnail
+			if e.config.SideBar.ShowThumbnail && ws.widget.IsVisible() {
+				ws.thumbnail = ws.screen.grabThumbnail()
+			}
 			ws.hide()
 		}
 	}
@@ -802,6 +902,11 @@ func (e *Editor) workspaceUpdate() {
 }
 
 func (e *Editor) keyRelease(event *gui.QKeyEvent) {
+	// Alt pressed and released alone toggles the menu bar
+	if e.isAltPressedAlone && event.Key() == int(core.Qt__Key_Alt) {
+		e.isAltPressedAlone = false
+		e.workspaces[e.active].menu.toggleBar()
+	}
 	if !e.isKeyAutoRepeating {
 		return
 	}
@@ -820,6 +925,7 @@ func (e *Editor) keyPress(event *gui.QKeyEvent) {
 	if event.IsAutoRepeat() {
 		e.isKeyAutoRepeating = true
 	}
+	e.isAltPressedAlone = event.Key() == int(core.Qt__Key_Alt) && !event.IsAutoRepeat()
 	e.putLog("key input:", input, fmt.Sprintf("%s, %d, %v", event.Text(), event.Key(), event.Modifiers()))
 	if input != "" {
 		e.workspaces[e.active].nvim.Input(input)
@@ -1055,6 +1161,8 @@ func (e *Editor) close() {
 }
 
 func (e *Editor) cleanup() {
+	e.saveMessageLogs()
+
 	sessions := filepath.Join(e.configDir, "sessions")
 	os.RemoveAll(sessions)
 	os.MkdirAll(sessions, 0755)
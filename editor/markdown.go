@@ -2,9 +2,22 @@ package editor
 
 import (
 	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"html/template"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/akiyosi/goneovim/util"
@@ -16,6 +29,12 @@ import (
 	"github.com/therecipe/qt/widgets"
 	"github.com/yuin/goldmark"
 	highlighting "github.com/yuin/goldmark-highlighting"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	gmutil "github.com/yuin/goldmark/util"
 )
 
 //
@@ -28,9 +47,18 @@ type Markdown struct {
 	webview         *webengine.QWebEngineView
 	webpage         *webengine.QWebEnginePage
 	ws              *Workspace
-	markdownUpdates chan string
+	markdownUpdates chan markdownContent
+	exportDocument  chan [2]string
 	container       *widgets.QPlainTextEdit
+	imageWatcher    *core.QFileSystemWatcher
 	htmlSet         bool
+	cursorLine      int
+}
+
+// markdownContent is the rendered preview body with the local images it refers to
+type markdownContent struct {
+	body   string
+	images []string
 }
 
 func newMarkdown(workspace *Workspace) *Markdown {
@@ -41,7 +69,8 @@ func newMarkdown(workspace *Workspace) *Markdown {
 	}
 	m := &Markdown{
 		webview:         webview,
-		markdownUpdates: make(chan string, 1000),
+		markdownUpdates: make(chan markdownContent, 1000),
+		exportDocument:  make(chan [2]string, 10),
 		ws:              workspace,
 	}
 	m.ws.signal.ConnectMarkdownSignal(func() {
@@ -53,31 +82,27 @@ func newMarkdown(workspace *Workspace) *Markdown {
 			channel.RegisterObject("content", m.container)
 			//m.webpage.SetWebChannel2(channel)
 			m.webpage.SetWebChannel(channel)
+			m.webpage.ConnectAcceptNavigationRequest(m.acceptNavigationRequest)
+			m.imageWatcher = core.NewQFileSystemWatcher(nil)
+			m.imageWatcher.ConnectFileChanged(m.reloadImage)
 		}
 		content := <-m.markdownUpdates
-		// Get file base path
-		done := make(chan error, 60)
-		var basePath string
-		var err error
-		go func() {
-			basePath, err = m.ws.nvim.CommandOutput(`echo expand('%:p:h')`)
-			done <- err
-		}()
-		select {
-		case <-done:
-		case <-time.After(40 * time.Millisecond):
-		}
 		// Create bae url
-		baseUrl := `file://` + basePath + `/`
+		baseUrl := `file://` + m.getBasePath() + `/`
 		if !m.htmlSet {
 			m.htmlSet = true
-			m.webpage.SetHtml(m.getHTML(content), core.NewQUrl3(baseUrl, 0))
+			m.webpage.SetHtml(m.getHTML(content.body), core.NewQUrl3(baseUrl, 0))
 		} else {
-			m.container.SetPlainTextDefault(content)
+			m.container.SetPlainTextDefault(content.body)
 			m.container.TextChanged()
 		}
+		m.watchImages(content.images)
 		m.updatePos()
 	})
+	m.ws.signal.ConnectMarkdownExportSignal(func() {
+		doc := <-m.exportDocument
+		m.printToPdf(doc[0], doc[1])
+	})
 	m.webview.ConnectEventFilter(func(watched *core.QObject, event *core.QEvent) bool {
 		if event.Type() == core.QEvent__KeyPress {
 			keyPress := gui.NewQKeyEventFromPointer(event.Pointer())
@@ -341,13 +366,122 @@ func (m *Markdown) newBuffer() {
 }
 
 func (m *Markdown) update() {
+	body, err := m.convertCurrentBuffer()
+	if rerr, ok := err.(*previewRenderError); ok {
+		// Show the error of the renderer instead of the document
+		body = fmt.Sprintf(`<pre class="gonvim-preview-error">%s</pre>`, template.HTMLEscapeString(rerr.Error()))
+	} else if err != nil {
+		return
+	}
+	body, images := resolveLocalReferences(body, m.getBasePath())
+
+	m.markdownUpdates <- markdownContent{
+		body: fmt.Sprintf(`
+			<div id="placeholder" class="markdown-body">
+			%s
+			</div>`, body),
+		images: images,
+	}
+	m.ws.signal.MarkdownSignal()
+}
+
+// watchImages watches the local images shown in the preview for changes on disk
+func (m *Markdown) watchImages(images []string) {
+	watched := m.imageWatcher.Files()
+	if len(watched) > 0 {
+		m.imageWatcher.RemovePaths(watched)
+	}
+	if len(images) > 0 {
+		m.imageWatcher.AddPaths(images)
+	}
+}
+
+// reloadImage reloads the images which refer to path in the preview
+func (m *Markdown) reloadImage(path string) {
+	// Editors which save by replacing the file drop it from the watch list
+	if isFileExist(path) {
+		m.imageWatcher.AddPath(path)
+	}
+	fileURL := core.QUrl_FromLocalFile(path).ToString(core.QUrl__FullyEncoded)
+	m.webpage.RunJavaScript(fmt.Sprintf(`
+		(function(url) {
+		  var images = document.getElementsByTagName('img');
+		  for (var i = 0; i < images.length; i++) {
+		    if (images[i].src.split('?')[0] === url) {
+		      images[i].src = url + '?t=' + Date.now();
+		    }
+		  }
+		})(%s);`, strconv.Quote(fileURL)))
+}
+
+// acceptNavigationRequest opens markdown links in neovim and
+// other links in the system browser instead of the preview.
+func (m *Markdown) acceptNavigationRequest(link *core.QUrl, ty webengine.QWebEnginePage__NavigationType, isMainFrame bool) bool {
+	if ty != webengine.QWebEnginePage__NavigationTypeLinkClicked {
+		return true
+	}
+	if !link.IsLocalFile() {
+		gui.QDesktopServices_OpenUrl(link)
+		return false
+	}
+
+	path := link.ToLocalFile()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".mkd", ".mdown":
+		go func() {
+			var escaped string
+			err := m.ws.nvim.Call("fnameescape", &escaped, path)
+			if err != nil {
+				return
+			}
+			m.ws.nvim.Command(fmt.Sprintf(
+				"if &filetype ==# '%s' | wincmd p | endif | edit %s",
+				GonvimMarkdownBufName,
+				escaped,
+			))
+		}()
+	default:
+		// Anchors in the preview itself refer to the base directory
+		if info, err := os.Stat(path); err == nil && info.IsDir() && link.HasFragment() {
+			return true
+		}
+		gui.QDesktopServices_OpenUrl(link)
+	}
+
+	return false
+}
+
+// convertCurrentBuffer converts the contents of the current buffer to HTML
+// with the renderer for its filetype
+func (m *Markdown) convertCurrentBuffer() (string, error) {
 	buf, err := m.ws.nvim.CurrentBuffer()
 	if err != nil {
-		return
+		return "", err
+	}
+	var filetype string
+	err = m.ws.nvim.BufferOption(buf, "filetype", &filetype)
+	if err != nil {
+		return "", err
+	}
+	path, err := m.ws.nvim.BufferName(buf)
+	if err != nil {
+		return "", err
+	}
+	render, ok := getPreviewRenderer(filetype, path)
+	if !ok {
+		return "", errors.New("no previewer for this buffer")
 	}
+	if isPreviewImage(path) {
+		body, err := render(nil, path)
+		if err != nil {
+			return "", &previewRenderError{err}
+		}
+		return body, nil
+	}
+
 	lines, err := m.ws.nvim.BufferLines(buf, 0, -1, false)
 	if err != nil {
-		return
+		return "", err
 	}
 	content := []byte{}
 	for _, line := range lines {
@@ -355,26 +489,481 @@ func (m *Markdown) update() {
 		content = append(content, '\n')
 	}
 
-	markdown := goldmark.New(
-		goldmark.WithExtensions(
-			highlighting.NewHighlighting(
-				highlighting.WithStyle(editor.config.Markdown.CodeHlStyle),
-				highlighting.WithFormatOptions(
-					html.WithLineNumbers(editor.config.Markdown.CodeWithLineNumbers),
-				),
+	body, err := render(content, path)
+	if err != nil {
+		return "", &previewRenderError{err}
+	}
+
+	return body, nil
+}
+
+// previewRenderError is the error of the renderer of a buffer, such as an
+// external previewer, as opposed to the errors of reading the buffer
+type previewRenderError struct {
+	err error
+}
+
+func (e *previewRenderError) Error() string {
+	return e.err.Error()
+}
+
+// newMarkdownConverter creates a goldmark converter with the extensions enabled in markdownConfig
+func newMarkdownConverter() goldmark.Markdown {
+	config := editor.config.Markdown
+	extensions := []goldmark.Extender{
+		highlighting.NewHighlighting(
+			highlighting.WithStyle(config.CodeHlStyle),
+			highlighting.WithFormatOptions(
+				html.WithLineNumbers(config.CodeWithLineNumbers),
+			),
+		),
+	}
+	if config.GFM {
+		extensions = append(extensions, extension.GFM)
+	}
+	if config.Footnote {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if config.DefinitionList {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if config.Math {
+		extensions = append(extensions, mathMarkdown)
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(
+			parser.WithASTTransformers(
+				gmutil.Prioritized(&lineNumberTransformer{}, 100),
+				gmutil.Prioritized(&taskListTransformer{}, 200),
 			),
 		),
 	)
-	var buff bytes.Buffer
-	if err := markdown.Convert([]byte(content), &buff); err != nil {
+}
+
+// blankFrontMatter replaces a leading YAML ("---") or TOML ("+++") front matter
+// block with empty lines, so that it is not rendered while the line numbers
+// of the following content stay the same.
+func blankFrontMatter(content []byte) []byte {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines) < 2 {
+		return content
+	}
+	delimiter := string(bytes.TrimRight(lines[0], " \t\r\n"))
+	if delimiter != "---" && delimiter != "+++" {
+		return content
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		line := string(bytes.TrimRight(lines[i], " \t\r\n"))
+		if line == delimiter || (delimiter == "---" && line == "...") {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return content
+	}
+
+	result := bytes.Repeat([]byte("\n"), end+1)
+	for _, line := range lines[end+1:] {
+		result = append(result, line...)
+	}
+
+	return result
+}
+
+// taskListTransformer marks list items that start with a GFM task checkbox,
+// so that the preview stylesheet can lay them out like GitHub does.
+type taskListTransformer struct{}
+
+func (t *taskListTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindListItem {
+			return ast.WalkContinue, nil
+		}
+		block := n.FirstChild()
+		if block == nil || block.FirstChild() == nil {
+			return ast.WalkContinue, nil
+		}
+		if block.FirstChild().Kind() == extast.KindTaskCheckBox {
+			n.SetAttributeString("class", []byte("task-list-item"))
+		}
+
+		return ast.WalkContinue, nil
+	})
+}
+
+// mathHead returns the tags which load KaTeX from MathRendererPath.
+// Math is rendered completely offline; if the KaTeX distribution is not found,
+// math is displayed as TeX source and the user is warned once. If inline is
+// true, the assets are embedded in the document instead of being referenced.
+func mathHead(inline bool) string {
+	if !editor.config.Markdown.Math {
+		return ""
+	}
+	dir := editor.config.Markdown.MathRendererPath
+	if dir == "" {
+		dir = filepath.Join(getResourcePath(), "runtime", "katex")
+	}
+	css := filepath.Join(dir, "katex.min.css")
+	js := filepath.Join(dir, "katex.min.js")
+	autoRender := filepath.Join(dir, "contrib", "auto-render.min.js")
+	if !isFileExist(js) || !isFileExist(autoRender) {
+		warnMissingKatex.Do(func() {
+			editor.putLog("KaTeX is not found in", dir)
+			editor.pushNotification(
+				NotifyWarn,
+				-1,
+				fmt.Sprintf("KaTeX is not found in %s, so math is shown as TeX source. Set Markdown.MathRendererPath to the directory of the KaTeX distribution.", dir),
+			)
+		})
+		return ""
+	}
+
+	head := ""
+	if inline {
+		cssContent, err := ioutil.ReadFile(css)
+		if err == nil {
+			head += "<style>" + embedCSSURLs(string(cssContent), dir) + "</style>\n"
+		}
+		for _, path := range []string{js, autoRender} {
+			jsContent, err := ioutil.ReadFile(path)
+			if err != nil {
+				return ""
+			}
+			head += "<script>" + string(jsContent) + "</script>\n"
+		}
+	} else {
+		head += fmt.Sprintf(`<link rel="stylesheet" href="file://%s">`+"\n", filepath.ToSlash(css))
+		head += fmt.Sprintf(`<script src="file://%s"></script>`+"\n", filepath.ToSlash(js))
+		head += fmt.Sprintf(`<script src="file://%s"></script>`+"\n", filepath.ToSlash(autoRender))
+	}
+
+	return head + `<script>
+  var renderMath = function(element) {
+    if (typeof renderMathInElement !== 'function') {
+      return;
+    }
+    renderMathInElement(element, {
+      delimiters: [
+        {left: '\\[', right: '\\]', display: true},
+        {left: '\\(', right: '\\)', display: false}
+      ],
+      throwOnError: false
+    });
+  }
+  document.addEventListener('DOMContentLoaded', function() {
+    renderMath(document.body);
+  });
+</script>
+`
+}
+
+// lineNumberTransformer annotates block nodes with the source line they start on.
+// The annotation is rendered as a "data-line" attribute, which the preview uses
+// to keep the block under the cursor visible.
+type lineNumberTransformer struct{}
+
+func (t *lineNumberTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	lineStarts := []int{0}
+	for i, b := range source {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		start, ok := blockStart(n)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		line := sort.Search(len(lineStarts), func(i int) bool {
+			return lineStarts[i] > start
+		})
+		n.SetAttributeString("data-line", []byte(strconv.Itoa(line)))
+
+		return ast.WalkContinue, nil
+	})
+}
+
+// blockStart returns the source offset of the first line of a block node.
+// Container blocks such as lists and blockquotes have no lines of their own,
+// so the offset of their first descendant is used.
+func blockStart(n ast.Node) (int, bool) {
+	if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+		return n.Lines().At(0).Start, true
+	}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if start, ok := blockStart(c); ok {
+			return start, true
+		}
+	}
+
+	return 0, false
+}
+
+// syncCursor scrolls the preview so that the block rendered from
+// the given source line stays visible.
+func (m *Markdown) syncCursor(line int) {
+	if !editor.config.Markdown.SyncScroll {
+		return
+	}
+	m.cursorLine = line
+	if m.webpage == nil || !m.htmlSet {
 		return
 	}
+	m.webpage.RunJavaScript(fmt.Sprintf(
+		"if (typeof scrollToLine === 'function') { scrollToLine(%d, %t); }",
+		line,
+		editor.config.Markdown.HighlightSyncedBlock,
+	))
+}
 
-	m.markdownUpdates <- fmt.Sprintf(`
-			<div id="placeholder" class="markdown-body">
-			%s
-			</div>`, buff.String())
-	m.ws.signal.MarkdownSignal()
+// getBasePath returns the directory of the current buffer
+func (m *Markdown) getBasePath() string {
+	done := make(chan error, 60)
+	var basePath string
+	var err error
+	go func() {
+		basePath, err = m.ws.nvim.CommandOutput(`echo expand('%:p:h')`)
+		done <- err
+	}()
+	select {
+	case <-done:
+	case <-time.After(40 * time.Millisecond):
+	}
+
+	return basePath
+}
+
+// export writes the current markdown buffer to path as a self-contained
+// HTML document, or as a PDF if path has the ".pdf" extension.
+func (m *Markdown) export(path string) {
+	if path == "" {
+		return
+	}
+	body, err := m.convertCurrentBuffer()
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Goneovim] Failed to export markdown: "+err.Error())
+		return
+	}
+	basePath := m.getBasePath()
+	document := getExportHTML(filepath.Base(path), embedLocalImages(body, basePath))
+
+	if strings.EqualFold(filepath.Ext(path), ".pdf") {
+		m.exportDocument <- [2]string{path, document}
+		m.ws.signal.MarkdownExportSignal()
+		return
+	}
+
+	err = ioutil.WriteFile(path, []byte(document), 0644)
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Goneovim] Failed to export markdown: "+err.Error())
+		return
+	}
+	editor.pushNotification(NotifyInfo, -1, "[Goneovim] Exported markdown to "+path)
+}
+
+// printToPdf renders the document offline with a hidden web page and prints it to a PDF file.
+// The document is loaded from a temporary file, as setHtml can not take more
+// than 2 MB, which the embedded images and fonts easily exceed.
+// This must be called in the UI thread.
+func (m *Markdown) printToPdf(path, document string) {
+	tmp, err := ioutil.TempFile("", "goneovim-export-*.html")
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Goneovim] Failed to export markdown: "+err.Error())
+		return
+	}
+	_, err = tmp.WriteString(document)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		editor.pushNotification(NotifyWarn, -1, "[Goneovim] Failed to export markdown: "+err.Error())
+		return
+	}
+
+	page := webengine.NewQWebEnginePage(nil)
+	page.ConnectLoadFinished(func(ok bool) {
+		if !ok {
+			editor.pushNotification(NotifyWarn, -1, "[Goneovim] Failed to render markdown for PDF export")
+			os.Remove(tmp.Name())
+			page.DeleteLater()
+			return
+		}
+		layout := gui.NewQPageLayout2(
+			gui.NewQPageSize2(gui.QPageSize__A4),
+			gui.QPageLayout__Portrait,
+			core.NewQMarginsF3(15, 15, 15, 15),
+			gui.QPageLayout__Millimeter,
+			core.NewQMarginsF(),
+		)
+		page.PrintToPdf(path, layout)
+	})
+	page.ConnectPdfPrintingFinished(func(filePath string, success bool) {
+		if success {
+			editor.pushNotification(NotifyInfo, -1, "[Goneovim] Exported markdown to "+filePath)
+		} else {
+			editor.pushNotification(NotifyWarn, -1, "[Goneovim] Failed to export markdown to "+filePath)
+		}
+		os.Remove(tmp.Name())
+		page.DeleteLater()
+	})
+	page.Load(core.QUrl_FromLocalFile(tmp.Name()))
+}
+
+// resolveLocalReferences rewrites relative image sources and links in body to
+// absolute file URLs based on basePath, because the preview document keeps the
+// base URL of the buffer it was created for. Image sources carry the
+// modification time of the file, so that the preview reloads changed images.
+// It also returns the paths of the referenced local images.
+func resolveLocalReferences(body, basePath string) (string, []string) {
+	var images []string
+	body = imgSrcRegexp.ReplaceAllStringFunc(body, func(tag string) string {
+		match := imgSrcRegexp.FindStringSubmatch(tag)
+		path, ok := localPath(match[2], basePath)
+		if !ok {
+			return tag
+		}
+		fileURL := localFileURL(path)
+		if info, err := os.Stat(path); err == nil {
+			images = append(images, path)
+			fileURL += "?t=" + strconv.FormatInt(info.ModTime().UnixNano(), 10)
+		}
+
+		return fmt.Sprintf(`%ssrc="%s"`, match[1], fileURL)
+	})
+	body = linkHrefRegexp.ReplaceAllStringFunc(body, func(tag string) string {
+		match := linkHrefRegexp.FindStringSubmatch(tag)
+		if strings.HasPrefix(match[2], "#") {
+			return tag
+		}
+		ref := match[2]
+		fragment := ""
+		if i := strings.Index(ref, "#"); i >= 0 {
+			ref, fragment = ref[:i], ref[i:]
+		}
+		path, ok := localPath(ref, basePath)
+		if !ok {
+			return tag
+		}
+		return fmt.Sprintf(`%shref="%s%s"`, match[1], localFileURL(path), fragment)
+	})
+
+	return body, images
+}
+
+// localPath returns the absolute path of the relative reference src, resolved against basePath
+func localPath(src, basePath string) (string, bool) {
+	if src == "" || strings.HasPrefix(src, "data:") || strings.Contains(src, ":") || filepath.IsAbs(src) {
+		return "", false
+	}
+	path := src
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+
+	return filepath.Join(basePath, filepath.FromSlash(path)), true
+}
+
+// localFileURL returns the file URL of the absolute path
+func localFileURL(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// embedLocalImages replaces the source of local images with data URIs
+// so that the exported document does not depend on files next to it.
+func embedLocalImages(body, basePath string) string {
+	return imgSrcRegexp.ReplaceAllStringFunc(body, func(tag string) string {
+		match := imgSrcRegexp.FindStringSubmatch(tag)
+		src := match[2]
+		if strings.HasPrefix(src, "data:") || strings.Contains(src, "://") && !strings.HasPrefix(src, "file://") {
+			return tag
+		}
+		uri, ok := dataURI(src, basePath)
+		if !ok {
+			return tag
+		}
+
+		return fmt.Sprintf(`%ssrc="%s"`, match[1], uri)
+	})
+}
+
+// embedCSSURLs replaces relative url() references in a stylesheet, such as fonts, with data URIs
+func embedCSSURLs(css, basePath string) string {
+	return cssURLRegexp.ReplaceAllStringFunc(css, func(ref string) string {
+		src := strings.Trim(cssURLRegexp.FindStringSubmatch(ref)[1], `"'`)
+		if strings.HasPrefix(src, "data:") || strings.Contains(src, "://") {
+			return ref
+		}
+		uri, ok := dataURI(src, basePath)
+		if !ok {
+			return ref
+		}
+
+		return fmt.Sprintf(`url("%s")`, uri)
+	})
+}
+
+// dataURI reads the local file src, resolved against basePath, and encodes it as a data URI
+func dataURI(src, basePath string) (string, bool) {
+	path := strings.TrimPrefix(src, "file://")
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(basePath, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), true
+}
+
+var (
+	imgSrcRegexp   = regexp.MustCompile(`(<img\s[^>]*?)src="([^"]*)"`)
+	linkHrefRegexp = regexp.MustCompile(`(<a\s[^>]*?)href="([^"]*)"`)
+	cssURLRegexp   = regexp.MustCompile(`url\(([^)]+)\)`)
+
+	// warnMissingKatex warns only once that math can not be rendered
+	warnMissingKatex sync.Once
+)
+
+func getExportHTML(title, body string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8">
+    <title>%s</title>
+    <style>
+     %s
+     .markdown-body { box-sizing: border-box; max-width: 980px; margin: 0 auto; padding: 45px; }
+    </style>
+    %s
+  </head>
+  <body>
+    <div class="markdown-body">
+    %s
+    </div>
+  </body>
+</html>
+`, template.HTMLEscapeString(title), markdownStyle, mathHead(true), body)
 }
 
 func (m *Markdown) getHTML(content string) string {
@@ -401,6 +990,36 @@ function getMethods(obj) {
   return result;
 }
 
+  var cursorLine = 0;
+  var cursorHighlight = false;
+
+  var scrollToLine = function(line, highlight) {
+    cursorLine = line;
+    cursorHighlight = highlight;
+    var target = null;
+    var elements = placeholder.querySelectorAll('[data-line]');
+    for (var i = 0; i < elements.length; i++) {
+      if (parseInt(elements[i].getAttribute('data-line'), 10) > line) {
+        break;
+      }
+      target = elements[i];
+    }
+    var highlighted = placeholder.querySelectorAll('.gonvim-cursor-block');
+    for (var i = 0; i < highlighted.length; i++) {
+      highlighted[i].classList.remove('gonvim-cursor-block');
+    }
+    if (target === null) {
+      return;
+    }
+    if (highlight) {
+      target.classList.add('gonvim-cursor-block');
+    }
+    var rect = target.getBoundingClientRect();
+    if (rect.top < 0 || rect.bottom > window.innerHeight) {
+      target.scrollIntoView({block: 'center'});
+    }
+  }
+
   new QWebChannel(qt.webChannelTransport,
     function(channel) {
       var content = channel.objects.content;
@@ -408,6 +1027,12 @@ function getMethods(obj) {
         var frag = document.createElement('div');
         frag.innerHTML = content.plainText;
         dd.apply(placeholder, dd.diff(placeholder, frag.firstElementChild));
+        if (typeof renderMath === 'function') {
+          renderMath(placeholder);
+        }
+        if (cursorLine > 0) {
+          scrollToLine(cursorLine, cursorHighlight);
+        }
         //placeholder.innerHTML = content.plainText;
         //morphdom(placeholder, content.plainText);
         //console.warn(document.body.innerHTML);
@@ -415,2175 +1040,2220 @@ function getMethods(obj) {
     }
   );
 `
-	style := `
-.markdown-body {
-  -ms-text-size-adjust: 100%;
-  -webkit-text-size-adjust: 100%;
-  line-height: 1.5;
-  color: #24292e;
-  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif, "Apple Color Emoji", "Segoe UI Emoji", "Segoe UI Symbol";
-  font-size: 16px;
-  line-height: 1.5;
-  word-wrap: break-word;
-}
+	morphdomjs := `
+(function(root, factory) {
+    if (typeof exports !== 'undefined') {
+        if (typeof module !== 'undefined' && module.exports) {
+            exports = module.exports = factory();
+        } else {
+            exports.diffDOM = factory();
+        }
+    } else if (typeof define === 'function') {
+        // AMD loader
+        define(factory);
+    } else {
+        // window in the browser, or exports on the server
+        root.diffDOM = factory();
+    }
+})(this, function() {
+    "use strict";
 
-.markdown-body .pl-c {
-  color: #6a737d;
-}
+    var diffcount;
 
-.markdown-body .pl-c1,
-.markdown-body .pl-s .pl-v {
-  color: #005cc5;
-}
+    var Diff = function(options) {
+        var diff = this;
+        if (options) {
+            var keys = Object.keys(options),
+                length = keys.length,
+                i;
+            for (i = 0; i < length; i++) {
+                diff[keys[i]] = options[keys[i]];
+            }
+        }
 
-.markdown-body .pl-e,
-.markdown-body .pl-en {
-  color: #6f42c1;
-}
+    };
 
-.markdown-body .pl-smi,
-.markdown-body .pl-s .pl-s1 {
-  color: #24292e;
-}
+    Diff.prototype = {
+        toString: function() {
+            return JSON.stringify(this);
+        },
+        setValue: function(aKey, aValue) {
+            this[aKey] = aValue;
+            return this;
+        }
+    };
 
-.markdown-body .pl-ent {
-  color: #22863a;
-}
+    var SubsetMapping = function SubsetMapping(a, b) {
+        this.oldValue = a;
+        this.newValue = b;
+    };
 
-.markdown-body .pl-k {
-  color: #d73a49;
-}
+    SubsetMapping.prototype = {
+        contains: function contains(subset) {
+            if (subset.length < this.length) {
+                return subset.newValue >= this.newValue && subset.newValue < this.newValue + this.length;
+            }
+            return false;
+        },
+        toString: function toString() {
+            return this.length + " element subset, first mapping: old " + this.oldValue + " ? new " + this.newValue;
+        }
+    };
 
-.markdown-body .pl-s,
-.markdown-body .pl-pds,
-.markdown-body .pl-s .pl-pse .pl-s1,
-.markdown-body .pl-sr,
-.markdown-body .pl-sr .pl-cce,
-.markdown-body .pl-sr .pl-sre,
-.markdown-body .pl-sr .pl-sra {
-  color: #032f62;
-}
+    var elementDescriptors = function(el) {
+        var output = [];
+        if (el.nodeName !== '#text' && el.nodeName !== '#comment') {
+            output.push(el.nodeName);
+            if (el.attributes) {
+                if (el.attributes['class']) {
+                    output.push(el.nodeName + '.' + el.attributes['class'].replace(/ /g, '.'));
+                }
+                if (el.attributes.id) {
+                    output.push(el.nodeName + '#' + el.attributes.id);
+                }
+            }
 
-.markdown-body .pl-v,
-.markdown-body .pl-smw {
-  color: #e36209;
-}
+        }
+        return output;
+    };
 
-.markdown-body .pl-bu {
-  color: #b31d28;
-}
+    var findUniqueDescriptors = function(li) {
+        var uniqueDescriptors = {},
+            duplicateDescriptors = {},
+            liLength = li.length,
+            nodeLength, node, descriptors, descriptor, inUnique, inDupes, i, j;
 
-.markdown-body .pl-ii {
-  color: #fafbfc;
-  background-color: #b31d28;
-}
+        for (i = 0; i < liLength; i++) {
+            node = li[i];
+            nodeLength = node.length;
+            descriptors = elementDescriptors(node);
+            for (j = 0; j < nodeLength; j++) {
+                descriptor = descriptors[j];
+                inUnique = descriptor in uniqueDescriptors;
+                inDupes = descriptor in duplicateDescriptors;
+                if (!inUnique && !inDupes) {
+                    uniqueDescriptors[descriptor] = true;
+                } else if (inUnique) {
+                    delete uniqueDescriptors[descriptor];
+                    duplicateDescriptors[descriptor] = true;
+                }
+            }
+        }
 
-.markdown-body .pl-c2 {
-  color: #fafbfc;
-  background-color: #d73a49;
-}
+        return uniqueDescriptors;
+    };
 
-.markdown-body .pl-c2::before {
-  content: "^M";
-}
+    var uniqueInBoth = function(l1, l2) {
+        var l1Unique = findUniqueDescriptors(l1),
+            l2Unique = findUniqueDescriptors(l2),
+            inBoth = {},
+            keys = Object.keys(l1Unique),
+            length = keys.length,
+            key,
+            i;
 
-.markdown-body .pl-sr .pl-cce {
-  font-weight: bold;
-  color: #22863a;
-}
+        for (i = 0; i < length; i++) {
+            key = keys[i];
+            if (l2Unique[key]) {
+                inBoth[key] = true;
+            }
+        }
 
-.markdown-body .pl-ml {
-  color: #735c0f;
-}
+        return inBoth;
+    };
 
-.markdown-body .pl-mh,
-.markdown-body .pl-mh .pl-en,
-.markdown-body .pl-ms {
-  font-weight: bold;
-  color: #005cc5;
-}
+    var removeDone = function(tree) {
+        delete tree.outerDone;
+        delete tree.innerDone;
+        delete tree.valueDone;
+        if (tree.childNodes) {
+            return tree.childNodes.every(removeDone);
+        } else {
+            return true;
+        }
+    };
 
-.markdown-body .pl-mi {
-  font-style: italic;
-  color: #24292e;
-}
+    var isEqual = function(e1, e2) {
 
-.markdown-body .pl-mb {
-  font-weight: bold;
-  color: #24292e;
-}
+        var e1Attributes, e2Attributes;
 
-.markdown-body .pl-md {
-  color: #b31d28;
-  background-color: #ffeef0;
-}
+        if (!['nodeName', 'value', 'checked', 'selected', 'data'].every(function(element) {
+                if (e1[element] !== e2[element]) {
+                    return false;
+                }
+                return true;
+            })) {
+            return false;
+        }
 
-.markdown-body .pl-mi1 {
-  color: #22863a;
-  background-color: #f0fff4;
-}
+        if (Boolean(e1.attributes) !== Boolean(e2.attributes)) {
+            return false;
+        }
 
-.markdown-body .pl-mc {
-  color: #e36209;
-  background-color: #ffebda;
-}
+        if (Boolean(e1.childNodes) !== Boolean(e2.childNodes)) {
+            return false;
+        }
 
-.markdown-body .pl-mi2 {
-  color: #f6f8fa;
-  background-color: #005cc5;
-}
+        if (e1.attributes) {
+            e1Attributes = Object.keys(e1.attributes);
+            e2Attributes = Object.keys(e2.attributes);
 
-.markdown-body .pl-mdr {
-  font-weight: bold;
-  color: #6f42c1;
-}
+            if (e1Attributes.length !== e2Attributes.length) {
+                return false;
+            }
+            if (!e1Attributes.every(function(attribute) {
+                    if (e1.attributes[attribute] !== e2.attributes[attribute]) {
+                        return false;
+                    }
+                })) {
+                return false;
+            }
+        }
 
-.markdown-body .pl-ba {
-  color: #586069;
-}
+        if (e1.childNodes) {
+            if (e1.childNodes.length !== e2.childNodes.length) {
+                return false;
+            }
+            if (!e1.childNodes.every(function(childNode, index) {
+                    return isEqual(childNode, e2.childNodes[index]);
+                })) {
 
-.markdown-body .pl-sg {
-  color: #959da5;
-}
+                return false;
+            }
 
-.markdown-body .pl-corl {
-  text-decoration: underline;
-  color: #032f62;
-}
+        }
 
-.markdown-body .octicon {
-  display: inline-block;
-  vertical-align: text-top;
-  fill: currentColor;
-}
+        return true;
 
-.markdown-body a {
-  background-color: transparent;
-}
+    };
 
-.markdown-body a:active,
-.markdown-body a:hover {
-  outline-width: 0;
-}
 
-.markdown-body strong {
-  font-weight: inherit;
-}
+    var roughlyEqual = function(e1, e2, uniqueDescriptors, sameSiblings, preventRecursion) {
+        var childUniqueDescriptors, nodeList1, nodeList2;
 
-.markdown-body strong {
-  font-weight: bolder;
-}
+        if (!e1 || !e2) {
+            return false;
+        }
 
-.markdown-body h1 {
-  font-size: 2em;
-  margin: 0.67em 0;
-}
+        if (e1.nodeName !== e2.nodeName) {
+            return false;
+        }
 
-.markdown-body img {
-  border-style: none;
-}
+        if (e1.nodeName === '#text') {
+            // Note that we initially don't care what the text content of a node is,
+            // the mere fact that it's the same tag and "has text" means it's roughly
+            // equal, and then we can find out the true text difference later.
+            return preventRecursion ? true : e1.data === e2.data;
+        }
 
-.markdown-body code,
-.markdown-body kbd,
-.markdown-body pre {
-  font-family: monospace, monospace;
-  font-size: 1em;
-}
 
-.markdown-body hr {
-  box-sizing: content-box;
-  height: 0;
-  overflow: visible;
-}
+        if (e1.nodeName in uniqueDescriptors) {
+            return true;
+        }
 
-.markdown-body input {
-  font: inherit;
-  margin: 0;
-}
+        if (e1.attributes && e2.attributes) {
 
-.markdown-body input {
-  overflow: visible;
-}
+            if (e1.attributes.id) {
+                if (e1.attributes.id !== e2.attributes.id) {
+                    return false;
+                } else {
+                    var idDescriptor = e1.nodeName + '#' + e1.attributes.id;
+                    if (idDescriptor in uniqueDescriptors) {
+                        return true;
+                    }
+                }
+            }
+            if (e1.attributes['class'] && e1.attributes['class'] === e2.attributes['class']) {
+                var classDescriptor = e1.nodeName + '.' + e1.attributes['class'].replace(/ /g, '.');
+                if (classDescriptor in uniqueDescriptors) {
+                    return true;
+                }
+            }
+        }
 
-.markdown-body [type="checkbox"] {
-  box-sizing: border-box;
-  padding: 0;
-}
+        if (sameSiblings) {
+            return true;
+        }
 
-.markdown-body * {
-  box-sizing: border-box;
-}
+        nodeList1 = e1.childNodes ? e1.childNodes.slice().reverse() : [];
+        nodeList2 = e2.childNodes ? e2.childNodes.slice().reverse() : [];
 
-.markdown-body input {
-  font-family: inherit;
-  font-size: inherit;
-  line-height: inherit;
-}
+        if (nodeList1.length !== nodeList2.length) {
+            return false;
+        }
 
-.markdown-body a {
-  color: #0366d6;
-  text-decoration: none;
-}
+        if (preventRecursion) {
+            return nodeList1.every(function(element, index) {
+                return element.nodeName === nodeList2[index].nodeName;
+            });
+        } else {
+            // note: we only allow one level of recursion at any depth. If 'preventRecursion'
+            // was not set, we must explicitly force it to true for child iterations.
+            childUniqueDescriptors = uniqueInBoth(nodeList1, nodeList2);
+            return nodeList1.every(function(element, index) {
+                return roughlyEqual(element, nodeList2[index], childUniqueDescriptors, true, true);
+            });
+        }
+    };
 
-.markdown-body a:hover {
-  text-decoration: underline;
-}
 
-.markdown-body strong {
-  font-weight: 600;
-}
+    var cloneObj = function(obj) {
+        //  TODO: Do we really need to clone here? Is it not enough to just return the original object?
+        return JSON.parse(JSON.stringify(obj));
+    };
 
-.markdown-body hr {
-  height: 0;
-  margin: 15px 0;
-  overflow: hidden;
-  background: transparent;
-  border: 0;
-  border-bottom: 1px solid #dfe2e5;
-}
+    /**
+     * based on https://en.wikibooks.org/wiki/Algorithm_implementation/Strings/Longest_common_substring#JavaScript
+     */
+    var findCommonSubsets = function(c1, c2, marked1, marked2) {
+        var lcsSize = 0,
+            index = [],
+            c1Length = c1.length,
+            c2Length = c2.length,
+            matches = Array.apply(null, new Array(c1Length + 1)).map(function() {
+                return [];
+            }), // set up the matching table
+            uniqueDescriptors = uniqueInBoth(c1, c2),
+            // If all of the elements are the same tag, id and class, then we can
+            // consider them roughly the same even if they have a different number of
+            // children. This will reduce removing and re-adding similar elements.
+            subsetsSame = c1Length === c2Length,
+            origin, ret, c1Index, c2Index, c1Element, c2Element;
 
-.markdown-body hr::before {
-  display: table;
-  content: "";
-}
+        if (subsetsSame) {
 
-.markdown-body hr::after {
-  display: table;
-  clear: both;
-  content: "";
-}
+            c1.some(function(element, i) {
+                var c1Desc = elementDescriptors(element),
+                    c2Desc = elementDescriptors(c2[i]);
+                if (c1Desc.length !== c2Desc.length) {
+                    subsetsSame = false;
+                    return true;
+                }
+                c1Desc.some(function(description, i) {
+                    if (description !== c2Desc[i]) {
+                        subsetsSame = false;
+                        return true;
+                    }
+                });
+                if (!subsetsSame) {
+                    return true;
+                }
 
-.markdown-body table {
-  border-spacing: 0;
-  border-collapse: collapse;
-}
+            });
+        }
 
-.markdown-body td,
-.markdown-body th {
-  padding: 0;
-}
+        // fill the matches with distance values
+        for (c1Index = 0; c1Index < c1Length; c1Index++) {
+            c1Element = c1[c1Index];
+            for (c2Index = 0; c2Index < c2Length; c2Index++) {
+                c2Element = c2[c2Index];
+                if (!marked1[c1Index] && !marked2[c2Index] && roughlyEqual(c1Element, c2Element, uniqueDescriptors, subsetsSame)) {
+                    matches[c1Index + 1][c2Index + 1] = (matches[c1Index][c2Index] ? matches[c1Index][c2Index] + 1 : 1);
+                    if (matches[c1Index + 1][c2Index + 1] >= lcsSize) {
+                        lcsSize = matches[c1Index + 1][c2Index + 1];
+                        index = [c1Index + 1, c2Index + 1];
+                    }
+                } else {
+                    matches[c1Index + 1][c2Index + 1] = 0;
+                }
+            }
+        }
 
-.markdown-body h1,
-.markdown-body h2,
-.markdown-body h3,
-.markdown-body h4,
-.markdown-body h5,
-.markdown-body h6 {
-  margin-top: 0;
-  margin-bottom: 0;
-}
+        if (lcsSize === 0) {
+            return false;
+        }
+        origin = [index[0] - lcsSize, index[1] - lcsSize];
+        ret = new SubsetMapping(origin[0], origin[1]);
+        ret.length = lcsSize;
 
-.markdown-body h1 {
-  font-size: 32px;
-  font-weight: 600;
-}
+        return ret;
+    };
 
-.markdown-body h2 {
-  font-size: 24px;
-  font-weight: 600;
-}
+    /**
+     * This should really be a predefined function in Array...
+     */
+    var makeArray = function(n, v) {
+        return Array.apply(null, new Array(n)).map(function() {
+            return v;
+        });
+    };
 
-.markdown-body h3 {
-  font-size: 20px;
-  font-weight: 600;
-}
+    /**
+     * Generate arrays that indicate which node belongs to which subset,
+     * or whether it's actually an orphan node, existing in only one
+     * of the two trees, rather than somewhere in both.
+     *
+     * So if t1 = <img><canvas><br>, t2 = <canvas><br><img>.
+     * The longest subset is "<canvas><br>" (length 2), so it will group 0.
+     * The second longest is "<img>" (length 1), so it will be group 1.
+     * gaps1 will therefore be [1,0,0] and gaps2 [0,0,1].
+     *
+     * If an element is not part of any group, it will stay being 'true', which
+     * is the initial value. For example:
+     * t1 = <img><p></p><br><canvas>, t2 = <b></b><br><canvas><img>
+     *
+     * The "<p></p>" and "<b></b>" do only show up in one of the two and will
+     * therefore be marked by "true". The remaining parts are parts of the
+     * groups 0 and 1:
+     * gaps1 = [1, true, 0, 0], gaps2 = [true, 0, 0, 1]
+     *
+     */
+    var getGapInformation = function(t1, t2, stable) {
 
-.markdown-body h4 {
-  font-size: 16px;
-  font-weight: 600;
-}
+        var gaps1 = t1.childNodes ? makeArray(t1.childNodes.length, true) : [],
+            gaps2 = t2.childNodes ? makeArray(t2.childNodes.length, true) : [],
+            group = 0,
+            length = stable.length,
+            i, j, endOld, endNew, subset;
 
-.markdown-body h5 {
-  font-size: 14px;
-  font-weight: 600;
-}
+        // give elements from the same subset the same group number
+        for (i = 0; i < length; i++) {
+            subset = stable[i];
+            endOld = subset.oldValue + subset.length;
+            endNew = subset.newValue + subset.length;
+            for (j = subset.oldValue; j < endOld; j += 1) {
+                gaps1[j] = group;
+            }
+            for (j = subset.newValue; j < endNew; j += 1) {
+                gaps2[j] = group;
+            }
+            group += 1;
+        }
 
-.markdown-body h6 {
-  font-size: 12px;
-  font-weight: 600;
-}
-
-.markdown-body p {
-  margin-top: 0;
-  margin-bottom: 10px;
-}
+        return {
+            gaps1: gaps1,
+            gaps2: gaps2
+        };
+    };
 
-.markdown-body blockquote {
-  margin: 0;
-}
+    /**
+     * Find all matching subsets, based on immediate child differences only.
+     */
+    var markSubTrees = function(oldTree, newTree) {
+        // note: the child lists are views, and so update as we update old/newTree
+        var oldChildren = oldTree.childNodes ? oldTree.childNodes : [],
+            newChildren = newTree.childNodes ? newTree.childNodes : [],
+            marked1 = makeArray(oldChildren.length, false),
+            marked2 = makeArray(newChildren.length, false),
+            subsets = [],
+            subset = true,
+            returnIndex = function() {
+                return arguments[1];
+            },
+            markBoth = function(i) {
+                marked1[subset.oldValue + i] = true;
+                marked2[subset.newValue + i] = true;
+            },
+            length, subsetArray, i;
 
-.markdown-body ul,
-.markdown-body ol {
-  padding-left: 0;
-  margin-top: 0;
-  margin-bottom: 0;
-}
+        while (subset) {
+            subset = findCommonSubsets(oldChildren, newChildren, marked1, marked2);
+            if (subset) {
+                subsets.push(subset);
+                subsetArray = Array.apply(null, new Array(subset.length)).map(returnIndex);
+                length = subsetArray.length;
+                for (i = 0; i < length; i++) {
+                    markBoth(subsetArray[i]);
+                }
+            }
+        }
+        return subsets;
+    };
 
-.markdown-body ol ol,
-.markdown-body ul ol {
-  list-style-type: lower-roman;
-}
 
-.markdown-body ul ul ol,
-.markdown-body ul ol ol,
-.markdown-body ol ul ol,
-.markdown-body ol ol ol {
-  list-style-type: lower-alpha;
-}
+    function swap(obj, p1, p2) {
+        var tmp = obj[p1];
+        obj[p1] = obj[p2];
+        obj[p2] = tmp;
+    }
 
-.markdown-body dd {
-  margin-left: 0;
-}
 
-.markdown-body code {
-  font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, Courier, monospace;
-  font-size: 12px;
-}
+    var DiffTracker = function() {
+        this.list = [];
+    };
 
-.markdown-body pre {
-  margin-top: 0;
-  margin-bottom: 0;
-  font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, Courier, monospace;
-  font-size: 12px;
-}
+    DiffTracker.prototype = {
+        list: false,
+        add: function(diffs) {
+            this.list.push.apply(this.list, diffs);
+        },
+        forEach: function(fn) {
+            var length = this.list.length,
+                i;
+            for (i = 0; i < length; i++) {
+                fn(this.list[i]);
+            }
+        }
+    };
 
-.markdown-body .octicon {
-  vertical-align: text-bottom;
-}
+    var diffDOM = function(options) {
 
-.markdown-body .pl-0 {
-  padding-left: 0 !important;
-}
+        var defaults = {
+                debug: false,
+                diffcap: 10, // Limit for how many diffs are accepting when debugging. Inactive when debug is false.
+                maxDepth: false, // False or a numeral. If set to a numeral, limits the level of depth that the the diff mechanism looks for differences. If false, goes through the entire tree.
+                valueDiffing: true, // Whether to take into consideration the values of forms that differ from auto assigned values (when a user fills out a form).
+                // syntax: textDiff: function (node, currentValue, expectedValue, newValue)
+                textDiff: function() {
+                    arguments[0].data = arguments[3];
+                    return;
+                },
+                // empty functions were benchmarked as running faster than both
+                // f && f() and if (f) { f(); }
+                preVirtualDiffApply: function() {},
+                postVirtualDiffApply: function() {},
+                preDiffApply: function() {},
+                postDiffApply: function() {},
+                filterOuterDiff: null,
+                compress: false // Whether to work with compressed diffs
+            },
+            varNames, i, j;
 
-.markdown-body .pl-1 {
-  padding-left: 4px !important;
-}
+        if (typeof options === "undefined") {
+            options = {};
+        }
 
-.markdown-body .pl-2 {
-  padding-left: 8px !important;
-}
+        for (i in defaults) {
+            if (typeof options[i] === "undefined") {
+                this[i] = defaults[i];
+            } else {
+                this[i] = options[i];
+            }
+        }
 
-.markdown-body .pl-3 {
-  padding-left: 16px !important;
-}
+        var varNames = {
+            'addAttribute': 'addAttribute',
+            'modifyAttribute': 'modifyAttribute',
+            'removeAttribute': 'removeAttribute',
+            'modifyTextElement': 'modifyTextElement',
+            'relocateGroup': 'relocateGroup',
+            'removeElement': 'removeElement',
+            'addElement': 'addElement',
+            'removeTextElement': 'removeTextElement',
+            'addTextElement': 'addTextElement',
+            'replaceElement': 'replaceElement',
+            'modifyValue': 'modifyValue',
+            'modifyChecked': 'modifyChecked',
+            'modifySelected': 'modifySelected',
+            'modifyComment': 'modifyComment',
+            'action': 'action',
+            'route': 'route',
+            'oldValue': 'oldValue',
+            'newValue': 'newValue',
+            'element': 'element',
+            'group': 'group',
+            'from': 'from',
+            'to': 'to',
+            'name': 'name',
+            'value': 'value',
+            'data': 'data',
+            'attributes': 'attributes',
+            'nodeName': 'nodeName',
+            'childNodes': 'childNodes',
+            'checked': 'checked',
+            'selected': 'selected'
+        };
 
-.markdown-body .pl-4 {
-  padding-left: 24px !important;
-}
+        if (this.compress) {
+            j = 0;
+            this._const = {};
+            for (i in varNames) {
+                this._const[i] = j;
+                j++;
+            }
+        } else {
+            this._const = varNames;
+        }
+    };
 
-.markdown-body .pl-5 {
-  padding-left: 32px !important;
-}
+    diffDOM.Diff = Diff;
 
-.markdown-body .pl-6 {
-  padding-left: 40px !important;
-}
+    diffDOM.prototype = {
 
-.markdown-body::before {
-  display: table;
-  content: "";
-}
+        // ===== Create a diff =====
 
-.markdown-body::after {
-  display: table;
-  clear: both;
-  content: "";
-}
+        diff: function(t1Node, t2Node) {
 
-.markdown-body>*:first-child {
-  margin-top: 0 !important;
-}
+            var t1 = this.nodeToObj(t1Node),
+                t2 = this.nodeToObj(t2Node);
 
-.markdown-body>*:last-child {
-  margin-bottom: 0 !important;
-}
+            diffcount = 0;
 
-.markdown-body a:not([href]) {
-  color: inherit;
-  text-decoration: none;
-}
+            if (this.debug) {
+                this.t1Orig = this.nodeToObj(t1Node);
+                this.t2Orig = this.nodeToObj(t2Node);
+            }
 
-.markdown-body .anchor {
-  float: left;
-  padding-right: 4px;
-  margin-left: -20px;
-  line-height: 1;
-}
+            this.tracker = new DiffTracker();
+            return this.findDiffs(t1, t2);
+        },
+        findDiffs: function(t1, t2) {
+            var diffs;
+            do {
+                if (this.debug) {
+                    diffcount += 1;
+                    if (diffcount > this.diffcap) {
+                        window.diffError = [this.t1Orig, this.t2Orig];
+                        throw new Error("surpassed diffcap:" + JSON.stringify(this.t1Orig) + " -> " + JSON.stringify(this.t2Orig));
+                    }
+                }
+                diffs = this.findNextDiff(t1, t2, []);
+                if (diffs.length === 0) {
+                    // Last check if the elements really are the same now.
+                    // If not, remove all info about being done and start over.
+                    // Sometimes a node can be marked as done, but the creation of subsequent diffs means that it has to be changed anyway.
+                    if (!isEqual(t1, t2)) {
+                        removeDone(t1);
+                        diffs = this.findNextDiff(t1, t2, []);
+                    }
+                }
 
-.markdown-body .anchor:focus {
-  outline: none;
-}
+                if (diffs.length > 0) {
+                    this.tracker.add(diffs);
+                    this.applyVirtual(t1, diffs);
+                }
+            } while (diffs.length > 0);
+            return this.tracker.list;
+        },
+        findNextDiff: function(t1, t2, route) {
+            var diffs, fdiffs;
 
-.markdown-body p,
-.markdown-body blockquote,
-.markdown-body ul,
-.markdown-body ol,
-.markdown-body dl,
-.markdown-body table,
-.markdown-body pre {
-  margin-top: 0;
-  margin-bottom: 16px;
-}
+            if (this.maxDepth && route.length > this.maxDepth) {
+                return [];
+            }
+            // outer differences?
+            if (!t1.outerDone) {
+                diffs = this.findOuterDiff(t1, t2, route);
+                if (this.filterOuterDiff) {
+                    fdiffs = this.filterOuterDiff(t1, t2, diffs);
+                    if (fdiffs) diffs = fdiffs;
+                }
+                if (diffs.length > 0) {
+                    t1.outerDone = true;
+                    return diffs;
+                } else {
+                    t1.outerDone = true;
+                }
+            }
+            // inner differences?
+            if (!t1.innerDone) {
+                diffs = this.findInnerDiff(t1, t2, route);
+                if (diffs.length > 0) {
+                    return diffs;
+                } else {
+                    t1.innerDone = true;
+                }
+            }
 
-.markdown-body hr {
-  height: 0.25em;
-  padding: 0;
-  margin: 24px 0;
-  background-color: #e1e4e8;
-  border: 0;
-}
+            if (this.valueDiffing && !t1.valueDone) {
+                // value differences?
+                diffs = this.findValueDiff(t1, t2, route);
 
-.markdown-body blockquote {
-  padding: 0 1em;
-  color: #6a737d;
-  border-left: 0.25em solid #dfe2e5;
-}
+                if (diffs.length > 0) {
+                    t1.valueDone = true;
+                    return diffs;
+                } else {
+                    t1.valueDone = true;
+                }
+            }
 
-.markdown-body blockquote>:first-child {
-  margin-top: 0;
-}
+            // no differences
+            return [];
+        },
+        findOuterDiff: function(t1, t2, route) {
+            var t = this;
+            var diffs = [],
+                attr,
+                attr1, attr2, attrLength, pos, i;
 
-.markdown-body blockquote>:last-child {
-  margin-bottom: 0;
-}
+            if (t1.nodeName !== t2.nodeName) {
+                return [new Diff()
+                    .setValue(t._const.action, t._const.replaceElement)
+                    .setValue(t._const.oldValue, cloneObj(t1))
+                    .setValue(t._const.newValue, cloneObj(t2))
+                    .setValue(t._const.route, route)
+                ];
+            }
 
-.markdown-body kbd {
-  display: inline-block;
-  padding: 3px 5px;
-  font-size: 11px;
-  line-height: 10px;
-  color: #444d56;
-  vertical-align: middle;
-  background-color: #fafbfc;
-  border: solid 1px #c6cbd1;
-  border-bottom-color: #959da5;
-  border-radius: 3px;
-  box-shadow: inset 0 -1px 0 #959da5;
-}
+            if (t1.data !== t2.data) {
+                // Comment or text node.
+                if (t1.nodeName === '#text') {
+                    return [new Diff()
+                        .setValue(t._const.action, t._const.modifyTextElement)
+                        .setValue(t._const.route, route)
+                        .setValue(t._const.oldValue, t1.data)
+                        .setValue(t._const.newValue, t2.data)
+                    ];
+                } else {
+                    return [new Diff()
+                        .setValue(t._const.action, t._const.modifyComment)
+                        .setValue(t._const.route, route)
+                        .setValue(t._const.oldValue, t1.data)
+                        .setValue(t._const.newValue, t2.data)
+                    ];
+                }
 
-.markdown-body h1,
-.markdown-body h2,
-.markdown-body h3,
-.markdown-body h4,
-.markdown-body h5,
-.markdown-body h6 {
-  margin-top: 24px;
-  margin-bottom: 16px;
-  font-weight: 600;
-  line-height: 1.25;
-}
+            }
 
-.markdown-body h1 .octicon-link,
-.markdown-body h2 .octicon-link,
-.markdown-body h3 .octicon-link,
-.markdown-body h4 .octicon-link,
-.markdown-body h5 .octicon-link,
-.markdown-body h6 .octicon-link {
-  color: #1b1f23;
-  vertical-align: middle;
-  visibility: hidden;
-}
 
-.markdown-body h1:hover .anchor,
-.markdown-body h2:hover .anchor,
-.markdown-body h3:hover .anchor,
-.markdown-body h4:hover .anchor,
-.markdown-body h5:hover .anchor,
-.markdown-body h6:hover .anchor {
-  text-decoration: none;
-}
+            attr1 = t1.attributes ? Object.keys(t1.attributes).sort() : [];
+            attr2 = t2.attributes ? Object.keys(t2.attributes).sort() : [];
 
-.markdown-body h1:hover .anchor .octicon-link,
-.markdown-body h2:hover .anchor .octicon-link,
-.markdown-body h3:hover .anchor .octicon-link,
-.markdown-body h4:hover .anchor .octicon-link,
-.markdown-body h5:hover .anchor .octicon-link,
-.markdown-body h6:hover .anchor .octicon-link {
-  visibility: visible;
-}
+            attrLength = attr1.length;
+            for (i = 0; i < attrLength; i++) {
+                attr = attr1[i];
+                pos = attr2.indexOf(attr);
+                if (pos === -1) {
+                    diffs.push(new Diff()
+                        .setValue(t._const.action, t._const.removeAttribute)
+                        .setValue(t._const.route, route)
+                        .setValue(t._const.name, attr)
+                        .setValue(t._const.value, t1.attributes[attr])
+                    );
+                } else {
+                    attr2.splice(pos, 1);
+                    if (t1.attributes[attr] !== t2.attributes[attr]) {
+                        diffs.push(new Diff()
+                            .setValue(t._const.action, t._const.modifyAttribute)
+                            .setValue(t._const.route, route)
+                            .setValue(t._const.name, attr)
+                            .setValue(t._const.oldValue, t1.attributes[attr])
+                            .setValue(t._const.newValue, t2.attributes[attr])
+                        );
+                    }
+                }
+            }
 
-.markdown-body h1 {
-  padding-bottom: 0.3em;
-  font-size: 2em;
-  border-bottom: 1px solid #eaecef;
-}
+            attrLength = attr2.length;
+            for (i = 0; i < attrLength; i++) {
+                attr = attr2[i];
+                diffs.push(new Diff()
+                    .setValue(t._const.action, t._const.addAttribute)
+                    .setValue(t._const.route, route)
+                    .setValue(t._const.name, attr)
+                    .setValue(t._const.value, t2.attributes[attr])
+                );
+            }
 
-.markdown-body h2 {
-  padding-bottom: 0.3em;
-  font-size: 1.5em;
-  border-bottom: 1px solid #eaecef;
-}
+            return diffs;
+        },
+        nodeToObj: function(aNode) {
+            var objNode = {},
+                dobj = this,
+                nodeArray, childNode, length, attribute, i;
+            objNode.nodeName = aNode.nodeName;
+            if (objNode.nodeName === '#text' || objNode.nodeName === '#comment') {
+                objNode.data = aNode.data;
+            } else {
+                if (aNode.attributes && aNode.attributes.length > 0) {
+                    objNode.attributes = {};
+                    nodeArray = Array.prototype.slice.call(aNode.attributes);
+                    length = nodeArray.length;
+                    for (i = 0; i < length; i++) {
+                        attribute = nodeArray[i];
+                        objNode.attributes[attribute.name] = attribute.value;
+                    }
+                }
+                if (objNode.nodeName === 'TEXTAREA') {
+                    objNode.value = aNode.value;
+                } else if (aNode.childNodes && aNode.childNodes.length > 0) {
+                    objNode.childNodes = [];
+                    nodeArray = Array.prototype.slice.call(aNode.childNodes);
+                    length = nodeArray.length;
+                    for (i = 0; i < length; i++) {
+                        childNode = nodeArray[i];
+                        objNode.childNodes.push(dobj.nodeToObj(childNode));
+                    }
+                }
+                if (this.valueDiffing) {
+                    if (aNode.checked !== undefined && aNode.type &&
+                        ['radio','checkbox'].indexOf(aNode.type.toLowerCase()) !== -1
+                    ) {
+                        objNode.checked = aNode.checked;
+                    } else if (aNode.value !== undefined) {
+                        objNode.value = aNode.value;
+                    }
+                    if (aNode.selected !== undefined) {
+                        objNode.selected = aNode.selected;
+                    }
+                }
+            }
+            return objNode;
+        },
+        objToNode: function(objNode, insideSvg) {
+            var node, dobj = this,
+                attribute, attributeArray, childNode, childNodeArray, length, i;
+            if (objNode.nodeName === '#text') {
+                node = document.createTextNode(objNode.data);
 
-.markdown-body h3 {
-  font-size: 1.25em;
-}
-
-.markdown-body h4 {
-  font-size: 1em;
-}
-
-.markdown-body h5 {
-  font-size: 0.875em;
-}
-
-.markdown-body h6 {
-  font-size: 0.85em;
-  color: #6a737d;
-}
-
-.markdown-body ul,
-.markdown-body ol {
-  padding-left: 2em;
-}
-
-.markdown-body ul ul,
-.markdown-body ul ol,
-.markdown-body ol ol,
-.markdown-body ol ul {
-  margin-top: 0;
-  margin-bottom: 0;
-}
+            } else if (objNode.nodeName === '#comment') {
+                node = document.createComment(objNode.data);
+            } else {
+                if (objNode.nodeName === 'svg' || insideSvg) {
+                    node = document.createElementNS('http://www.w3.org/2000/svg', objNode.nodeName);
+                    insideSvg = true;
+                } else {
+                    node = document.createElement(objNode.nodeName);
+                }
+                if (objNode.attributes) {
+                    attributeArray = Object.keys(objNode.attributes);
+                    length = attributeArray.length;
+                    for (i = 0; i < length; i++) {
+                        attribute = attributeArray[i];
+                        node.setAttribute(attribute, objNode.attributes[attribute]);
+                    }
+                }
+                if (objNode.childNodes) {
+                    childNodeArray = objNode.childNodes;
+                    length = childNodeArray.length;
+                    for (i = 0; i < length; i++) {
+                        childNode = childNodeArray[i];
+                        node.appendChild(dobj.objToNode(childNode, insideSvg));
+                    }
+                }
+                if (this.valueDiffing) {
+                    if (objNode.value) {
+                        node.value = objNode.value;
+                    }
+                    if (objNode.checked) {
+                        node.checked = objNode.checked;
+                    }
+                    if (objNode.selected) {
+                        node.selected = objNode.selected;
+                    }
+                }
+            }
+            return node;
+        },
+        findInnerDiff: function(t1, t2, route) {
+            var t = this;
+            var subtrees = (t1.childNodes && t2.childNodes) ? markSubTrees(t1, t2) : [],
+                t1ChildNodes = t1.childNodes ? t1.childNodes : [],
+                t2ChildNodes = t2.childNodes ? t2.childNodes : [],
+                childNodesLengthDifference, diffs = [],
+                index = 0,
+                last, e1, e2, i;
 
-.markdown-body li {
-  word-wrap: break-all;
-}
+            if (subtrees.length > 0) {
+                /* One or more groups have been identified among the childnodes of t1
+                 * and t2.
+                 */
+                diffs = this.attemptGroupRelocation(t1, t2, subtrees, route);
+                if (diffs.length > 0) {
+                    return diffs;
+                }
+            }
 
-.markdown-body li>p {
-  margin-top: 16px;
-}
+            /* 0 or 1 groups of similar child nodes have been found
+             * for t1 and t2. 1 If there is 1, it could be a sign that the
+             * contents are the same. When the number of groups is below 2,
+             * t1 and t2 are made to have the same length and each of the
+             * pairs of child nodes are diffed.
+             */
 
-.markdown-body li+li {
-  margin-top: 0.25em;
-}
 
-.markdown-body dl {
-  padding: 0;
-}
+            last = Math.max(t1ChildNodes.length, t2ChildNodes.length);
+            if (t1ChildNodes.length !== t2ChildNodes.length) {
+                childNodesLengthDifference = true;
+            }
 
-.markdown-body dl dt {
-  padding: 0;
-  margin-top: 16px;
-  font-size: 1em;
-  font-style: italic;
-  font-weight: 600;
-}
+            for (i = 0; i < last; i += 1) {
+                e1 = t1ChildNodes[i];
+                e2 = t2ChildNodes[i];
 
-.markdown-body dl dd {
-  padding: 0 16px;
-  margin-bottom: 16px;
-}
+                if (childNodesLengthDifference) {
+                    /* t1 and t2 have different amounts of childNodes. Add
+                     * and remove as necessary to obtain the same length */
+                    if (e1 && !e2) {
+                        if (e1.nodeName === '#text') {
+                            diffs.push(new Diff()
+                                .setValue(t._const.action, t._const.removeTextElement)
+                                .setValue(t._const.route, route.concat(index))
+                                .setValue(t._const.value, e1.data)
+                            );
+                            index -= 1;
+                        } else {
+                            diffs.push(new Diff()
+                                .setValue(t._const.action, t._const.removeElement)
+                                .setValue(t._const.route, route.concat(index))
+                                .setValue(t._const.element, cloneObj(e1))
+                            );
+                            index -= 1;
+                        }
 
-.markdown-body table {
-  display: block;
-  width: 100%;
-  overflow: auto;
-}
+                    } else if (e2 && !e1) {
+                        if (e2.nodeName === '#text') {
+                            diffs.push(new Diff()
+                                .setValue(t._const.action, t._const.addTextElement)
+                                .setValue(t._const.route, route.concat(index))
+                                .setValue(t._const.value, e2.data)
+                            );
+                        } else {
+                            diffs.push(new Diff()
+                                .setValue(t._const.action, t._const.addElement)
+                                .setValue(t._const.route, route.concat(index))
+                                .setValue(t._const.element, cloneObj(e2))
+                            );
+                        }
+                    }
+                }
+                /* We are now guaranteed that childNodes e1 and e2 exist,
+                 * and that they can be diffed.
+                 */
+                /* Diffs in child nodes should not affect the parent node,
+                 * so we let these diffs be submitted together with other
+                 * diffs.
+                 */
 
-.markdown-body table th {
-  font-weight: 600;
-}
+                if (e1 && e2) {
+                    diffs = diffs.concat(this.findNextDiff(e1, e2, route.concat(index)));
+                }
 
-.markdown-body table th,
-.markdown-body table td {
-  padding: 6px 13px;
-  border: 1px solid #dfe2e5;
-}
+                index += 1;
 
-.markdown-body table tr {
-  background-color: #fff;
-  border-top: 1px solid #c6cbd1;
-}
+            }
+            t1.innerDone = true;
+            return diffs;
 
-.markdown-body table tr:nth-child(2n) {
-  background-color: #f6f8fa;
-}
+        },
 
-.markdown-body img {
-  max-width: 100%;
-  box-sizing: content-box;
-  background-color: #fff;
-}
+        attemptGroupRelocation: function(t1, t2, subtrees, route) {
+            /* Either t1.childNodes and t2.childNodes have the same length, or
+             * there are at least two groups of similar elements can be found.
+             * attempts are made at equalizing t1 with t2. First all initial
+             * elements with no group affiliation (gaps=true) are removed (if
+             * only in t1) or added (if only in t2). Then the creation of a group
+             * relocation diff is attempted.
+             */
+            var t = this;
+            var gapInformation = getGapInformation(t1, t2, subtrees),
+                gaps1 = gapInformation.gaps1,
+                gaps2 = gapInformation.gaps2,
+                shortest = Math.min(gaps1.length, gaps2.length),
+                destinationDifferent, toGroup,
+                group, node, similarNode, testI, diffs = [],
+                index1, index2, j;
 
-.markdown-body img[align=right] {
-  padding-left: 20px;
-}
 
-.markdown-body img[align=left] {
-  padding-right: 20px;
-}
+            for (index2 = 0, index1 = 0; index2 < shortest; index1 += 1, index2 += 1) {
+                if (gaps1[index2] === true) {
+                    node = t1.childNodes[index1];
+                    if (node.nodeName === '#text') {
+                        if (t2.childNodes[index2].nodeName === '#text' && node.data !== t2.childNodes[index2].data) {
+                            testI = index1;
+                            while (t1.childNodes.length > testI + 1 && t1.childNodes[testI + 1].nodeName === '#text') {
+                                testI += 1;
+                                if (t2.childNodes[index2].data === t1.childNodes[testI].data) {
+                                    similarNode = true;
+                                    break;
+                                }
+                            }
+                            if (!similarNode) {
+                                diffs.push(new Diff()
+                                    .setValue(t._const.action, t._const.modifyTextElement)
+                                    .setValue(t._const.route, route.concat(index2))
+                                    .setValue(t._const.oldValue, node.data)
+                                    .setValue(t._const.newValue, t2.childNodes[index2].data)
+                                );
+                                return diffs;
+                            }
+                        }
+                        diffs.push(new Diff()
+                            .setValue(t._const.action, t._const.removeTextElement)
+                            .setValue(t._const.route, route.concat(index2))
+                            .setValue(t._const.value, node.data)
+                        );
+                        gaps1.splice(index2, 1);
+                        shortest = Math.min(gaps1.length, gaps2.length);
+                        index2 -= 1;
+                    } else {
+                        diffs.push(new Diff()
+                            .setValue(t._const.action, t._const.removeElement)
+                            .setValue(t._const.route, route.concat(index2))
+                            .setValue(t._const.element, cloneObj(node))
+                        );
+                        gaps1.splice(index2, 1);
+                        shortest = Math.min(gaps1.length, gaps2.length);
+                        index2 -= 1;
+                    }
 
-.markdown-body code {
-  padding: 0.2em 0.4em;
-  margin: 0;
-  font-size: 85%;
-  background-color: rgba(27,31,35,0.05);
-  border-radius: 3px;
-}
+                } else if (gaps2[index2] === true) {
+                    node = t2.childNodes[index2];
+                    if (node.nodeName === '#text') {
+                        diffs.push(new Diff()
+                            .setValue(t._const.action, t._const.addTextElement)
+                            .setValue(t._const.route, route.concat(index2))
+                            .setValue(t._const.value, node.data)
+                        );
+                        gaps1.splice(index2, 0, true);
+                        shortest = Math.min(gaps1.length, gaps2.length);
+                        index1 -= 1;
+                    } else {
+                        diffs.push(new Diff()
+                            .setValue(t._const.action, t._const.addElement)
+                            .setValue(t._const.route, route.concat(index2))
+                            .setValue(t._const.element, cloneObj(node))
+                        );
+                        gaps1.splice(index2, 0, true);
+                        shortest = Math.min(gaps1.length, gaps2.length);
+                        index1 -= 1;
+                    }
 
-.markdown-body pre {
-  word-wrap: normal;
-}
+                } else if (gaps1[index2] !== gaps2[index2]) {
+                    if (diffs.length > 0) {
+                        return diffs;
+                    }
+                    // group relocation
+                    group = subtrees[gaps1[index2]];
+                    toGroup = Math.min(group.newValue, (t1.childNodes.length - group.length));
+                    if (toGroup !== group.oldValue) {
+                        // Check whether destination nodes are different than originating ones.
+                        destinationDifferent = false;
+                        for (j = 0; j < group.length; j += 1) {
+                            if (!roughlyEqual(t1.childNodes[toGroup + j], t1.childNodes[group.oldValue + j], [], false, true)) {
+                                destinationDifferent = true;
+                            }
+                        }
+                        if (destinationDifferent) {
+                            return [new Diff()
+                                .setValue(t._const.action, t._const.relocateGroup)
+                                .setValue('groupLength', group.length)
+                                .setValue(t._const.from, group.oldValue)
+                                .setValue(t._const.to, toGroup)
+                                .setValue(t._const.route, route)
+                            ];
+                        }
+                    }
+                }
+            }
+            return diffs;
+        },
 
-.markdown-body pre>code {
-  padding: 0;
-  margin: 0;
-  font-size: 100%;
-  word-break: normal;
-  white-space: pre;
-  background: transparent;
-  border: 0;
-}
+        findValueDiff: function(t1, t2, route) {
+            // Differences of value. Only useful if the value/selection/checked value
+            // differs from what is represented in the DOM. For example in the case
+            // of filled out forms, etc.
+            var diffs = [];
+            var t = this;
 
-.markdown-body .highlight {
-  margin-bottom: 16px;
-}
+            if (t1.selected !== t2.selected) {
+                diffs.push(new Diff()
+                    .setValue(t._const.action, t._const.modifySelected)
+                    .setValue(t._const.oldValue, t1.selected)
+                    .setValue(t._const.newValue, t2.selected)
+                    .setValue(t._const.route, route)
+                );
+            }
 
-.markdown-body .highlight pre {
-  margin-bottom: 0;
-  word-break: normal;
-}
+            if ((t1.value || t2.value) && t1.value !== t2.value && t1.nodeName !== 'OPTION') {
+                diffs.push(new Diff()
+                    .setValue(t._const.action, t._const.modifyValue)
+                    .setValue(t._const.oldValue, t1.value)
+                    .setValue(t._const.newValue, t2.value)
+                    .setValue(t._const.route, route)
+                );
+            }
+            if (t1.checked !== t2.checked) {
+                diffs.push(new Diff()
+                    .setValue(t._const.action, t._const.modifyChecked)
+                    .setValue(t._const.oldValue, t1.checked)
+                    .setValue(t._const.newValue, t2.checked)
+                    .setValue(t._const.route, route)
+                );
+            }
 
-.markdown-body .highlight pre,
-.markdown-body pre {
-  padding: 16px;
-  overflow: auto;
-  font-size: 85%;
-  line-height: 1.45;
-  background-color: #f6f8fa;
-  border-radius: 3px;
-}
+            return diffs;
+        },
 
-.markdown-body pre code {
-  display: inline;
-  max-width: auto;
-  padding: 0;
-  margin: 0;
-  overflow: visible;
-  line-height: inherit;
-  word-wrap: normal;
-  background-color: transparent;
-  border: 0;
-}
+        // ===== Apply a virtual diff =====
 
-.markdown-body .full-commit .btn-outline:not(:disabled):hover {
-  color: #005cc5;
-  border-color: #005cc5;
-}
+        applyVirtual: function(tree, diffs) {
+            var dobj = this,
+                length = diffs.length,
+                diff, i;
+            if (length === 0) {
+                return true;
+            }
+            for (i = 0; i < length; i++) {
+                diff = diffs[i];
+                dobj.applyVirtualDiff(tree, diff);
+            }
+            return true;
+        },
+        getFromVirtualRoute: function(tree, route) {
+            var node = tree,
+                parentNode, nodeIndex;
 
-.markdown-body kbd {
-  display: inline-block;
-  padding: 3px 5px;
-  font: 11px "SFMono-Regular", Consolas, "Liberation Mono", Menlo, Courier, monospace;
-  line-height: 10px;
-  color: #444d56;
-  vertical-align: middle;
-  background-color: #fafbfc;
-  border: solid 1px #d1d5da;
-  border-bottom-color: #c6cbd1;
-  border-radius: 3px;
-  box-shadow: inset 0 -1px 0 #c6cbd1;
-}
+            route = route.slice();
+            while (route.length > 0) {
+                if (!node.childNodes) {
+                    return false;
+                }
+                nodeIndex = route.splice(0, 1)[0];
+                parentNode = node;
+                node = node.childNodes[nodeIndex];
+            }
+            return {
+                node: node,
+                parentNode: parentNode,
+                nodeIndex: nodeIndex
+            };
+        },
+        applyVirtualDiff: function(tree, diff) {
+            var routeInfo = this.getFromVirtualRoute(tree, diff[this._const.route]),
+                node = routeInfo.node,
+                parentNode = routeInfo.parentNode,
+                nodeIndex = routeInfo.nodeIndex,
+                newNode, movedNode, nodeArray, route, length, c, i;
 
-.markdown-body :checked+.radio-label {
-  position: relative;
-  z-index: 1;
-  border-color: #0366d6;
-}
+            var t = this;
+            // pre-diff hook
+            var info = {
+                diff: diff,
+                node: node
+            };
 
-.markdown-body .task-list-item {
-  list-style-type: none;
-}
+            if (this.preVirtualDiffApply(info)) {
+                return true;
+            }
 
-.markdown-body .task-list-item+.task-list-item {
-  margin-top: 3px;
-}
+            switch (diff[this._const.action]) {
+                case this._const.addAttribute:
+                    if (!node.attributes) {
+                        node.attributes = {};
+                    }
 
-.markdown-body .task-list-item input {
-  margin: 0 0.2em 0.25em -1.6em;
-  vertical-align: middle;
-}
+                    node.attributes[diff[this._const.name]] = diff[this._const.value];
 
-.markdown-body hr {
-  border-bottom-color: #eee;
-}
-`
-	morphdomjs := `
-(function(root, factory) {
-    if (typeof exports !== 'undefined') {
-        if (typeof module !== 'undefined' && module.exports) {
-            exports = module.exports = factory();
-        } else {
-            exports.diffDOM = factory();
-        }
-    } else if (typeof define === 'function') {
-        // AMD loader
-        define(factory);
-    } else {
-        // window in the browser, or exports on the server
-        root.diffDOM = factory();
-    }
-})(this, function() {
-    "use strict";
+                    if (diff[this._const.name] === 'checked') {
+                        node.checked = true;
+                    } else if (diff[this._const.name] === 'selected') {
+                        node.selected = true;
+                    } else if (node.nodeName === 'INPUT' && diff[this._const.name] === 'value') {
+                        node.value = diff[this._const.value];
+                    }
 
-    var diffcount;
+                    break;
+                case this._const.modifyAttribute:
+                    node.attributes[diff[this._const.name]] = diff[this._const.newValue];
+                    if (node.nodeName === 'INPUT' && diff[this._const.name] === 'value') {
+                        node.value = diff[this._const.value];
+                    }
+                    break;
+                case this._const.removeAttribute:
 
-    var Diff = function(options) {
-        var diff = this;
-        if (options) {
-            var keys = Object.keys(options),
-                length = keys.length,
-                i;
-            for (i = 0; i < length; i++) {
-                diff[keys[i]] = options[keys[i]];
-            }
-        }
-
-    };
-
-    Diff.prototype = {
-        toString: function() {
-            return JSON.stringify(this);
-        },
-        setValue: function(aKey, aValue) {
-            this[aKey] = aValue;
-            return this;
-        }
-    };
+                    delete node.attributes[diff[this._const.name]];
 
-    var SubsetMapping = function SubsetMapping(a, b) {
-        this.oldValue = a;
-        this.newValue = b;
-    };
+                    if (Object.keys(node.attributes).length === 0) {
+                        delete node.attributes;
+                    }
 
-    SubsetMapping.prototype = {
-        contains: function contains(subset) {
-            if (subset.length < this.length) {
-                return subset.newValue >= this.newValue && subset.newValue < this.newValue + this.length;
-            }
-            return false;
-        },
-        toString: function toString() {
-            return this.length + " element subset, first mapping: old " + this.oldValue + " ? new " + this.newValue;
-        }
-    };
+                    if (diff[this._const.name] === 'checked') {
+                        node.checked = false;
+                    } else if (diff[this._const.name] === 'selected') {
+                        delete node.selected;
+                    } else if (node.nodeName === 'INPUT' && diff[this._const.name] === 'value') {
+                        delete node.value;
+                    }
 
-    var elementDescriptors = function(el) {
-        var output = [];
-        if (el.nodeName !== '#text' && el.nodeName !== '#comment') {
-            output.push(el.nodeName);
-            if (el.attributes) {
-                if (el.attributes['class']) {
-                    output.push(el.nodeName + '.' + el.attributes['class'].replace(/ /g, '.'));
-                }
-                if (el.attributes.id) {
-                    output.push(el.nodeName + '#' + el.attributes.id);
-                }
-            }
+                    break;
+                case this._const.modifyTextElement:
+                    node.data = diff[this._const.newValue];
+                    break;
+                case this._const.modifyValue:
+                    node.value = diff[this._const.newValue];
+                    break;
+                case this._const.modifyComment:
+                    node.data = diff[this._const.newValue];
+                    break;
+                case this._const.modifyChecked:
+                    node.checked = diff[this._const.newValue];
+                    break;
+                case this._const.modifySelected:
+                    node.selected = diff[this._const.newValue];
+                    break;
+                case this._const.replaceElement:
+                    newNode = cloneObj(diff[this._const.newValue]);
+                    newNode.outerDone = true;
+                    newNode.innerDone = true;
+                    newNode.valueDone = true;
+                    parentNode.childNodes[nodeIndex] = newNode;
+                    break;
+                case this._const.relocateGroup:
+                    nodeArray = node.childNodes.splice(diff[this._const.from], diff.groupLength).reverse();
+                    length = nodeArray.length;
+                    for (i = 0; i < length; i++) {
+                        movedNode = nodeArray[i];
+                        node.childNodes.splice(diff[t._const.to], 0, movedNode);
+                    }
+                    break;
+                case this._const.removeElement:
+                    parentNode.childNodes.splice(nodeIndex, 1);
+                    break;
+                case this._const.addElement:
+                    route = diff[this._const.route].slice();
+                    c = route.splice(route.length - 1, 1)[0];
+                    node = this.getFromVirtualRoute(tree, route).node;
+                    newNode = cloneObj(diff[this._const.element]);
+                    newNode.outerDone = true;
+                    newNode.innerDone = true;
+                    newNode.valueDone = true;
 
-        }
-        return output;
-    };
+                    if (!node.childNodes) {
+                        node.childNodes = [];
+                    }
 
-    var findUniqueDescriptors = function(li) {
-        var uniqueDescriptors = {},
-            duplicateDescriptors = {},
-            liLength = li.length,
-            nodeLength, node, descriptors, descriptor, inUnique, inDupes, i, j;
+                    if (c >= node.childNodes.length) {
+                        node.childNodes.push(newNode);
+                    } else {
+                        node.childNodes.splice(c, 0, newNode);
+                    }
+                    break;
+                case this._const.removeTextElement:
+                    parentNode.childNodes.splice(nodeIndex, 1);
+                    if (parentNode.nodeName === 'TEXTAREA') {
+                        delete parentNode.value;
+                    }
+                    break;
+                case this._const.addTextElement:
+                    route = diff[this._const.route].slice();
+                    c = route.splice(route.length - 1, 1)[0];
+                    newNode = {};
+                    newNode.nodeName = '#text';
+                    newNode.data = diff[this._const.value];
+                    node = this.getFromVirtualRoute(tree, route).node;
+                    if (!node.childNodes) {
+                        node.childNodes = [];
+                    }
 
-        for (i = 0; i < liLength; i++) {
-            node = li[i];
-            nodeLength = node.length;
-            descriptors = elementDescriptors(node);
-            for (j = 0; j < nodeLength; j++) {
-                descriptor = descriptors[j];
-                inUnique = descriptor in uniqueDescriptors;
-                inDupes = descriptor in duplicateDescriptors;
-                if (!inUnique && !inDupes) {
-                    uniqueDescriptors[descriptor] = true;
-                } else if (inUnique) {
-                    delete uniqueDescriptors[descriptor];
-                    duplicateDescriptors[descriptor] = true;
-                }
+                    if (c >= node.childNodes.length) {
+                        node.childNodes.push(newNode);
+                    } else {
+                        node.childNodes.splice(c, 0, newNode);
+                    }
+                    if (node.nodeName === 'TEXTAREA') {
+                        node.value = diff[this._const.newValue];
+                    }
+                    break;
+                default:
+                    console.log('unknown action');
             }
-        }
 
-        return uniqueDescriptors;
-    };
+            // capture newNode for the callback
+            info.newNode = newNode;
+            this.postVirtualDiffApply(info);
 
-    var uniqueInBoth = function(l1, l2) {
-        var l1Unique = findUniqueDescriptors(l1),
-            l2Unique = findUniqueDescriptors(l2),
-            inBoth = {},
-            keys = Object.keys(l1Unique),
-            length = keys.length,
-            key,
-            i;
+            return;
+        },
 
-        for (i = 0; i < length; i++) {
-            key = keys[i];
-            if (l2Unique[key]) {
-                inBoth[key] = true;
-            }
-        }
 
-        return inBoth;
-    };
 
-    var removeDone = function(tree) {
-        delete tree.outerDone;
-        delete tree.innerDone;
-        delete tree.valueDone;
-        if (tree.childNodes) {
-            return tree.childNodes.every(removeDone);
-        } else {
-            return true;
-        }
-    };
 
-    var isEqual = function(e1, e2) {
+        // ===== Apply a diff =====
 
-        var e1Attributes, e2Attributes;
+        apply: function(tree, diffs) {
+            var dobj = this,
+                length = diffs.length,
+                diff, i;
 
-        if (!['nodeName', 'value', 'checked', 'selected', 'data'].every(function(element) {
-                if (e1[element] !== e2[element]) {
+            if (length === 0) {
+                return true;
+            }
+            for (i = 0; i < length; i++) {
+                diff = diffs[i];
+                if (!dobj.applyDiff(tree, diff)) {
                     return false;
                 }
-                return true;
-            })) {
-            return false;
-        }
-
-        if (Boolean(e1.attributes) !== Boolean(e2.attributes)) {
-            return false;
-        }
-
-        if (Boolean(e1.childNodes) !== Boolean(e2.childNodes)) {
-            return false;
-        }
+            }
+            return true;
+        },
+        getFromRoute: function(tree, route) {
+            route = route.slice();
+            var c, node = tree;
+            while (route.length > 0) {
+                if (!node.childNodes) {
+                    return false;
+                }
+                c = route.splice(0, 1)[0];
+                node = node.childNodes[c];
+            }
+            return node;
+        },
+        applyDiff: function(tree, diff) {
+            var node = this.getFromRoute(tree, diff[this._const.route]),
+                newNode, reference, route, nodeArray, length, childNode, index, c;
 
-        if (e1.attributes) {
-            e1Attributes = Object.keys(e1.attributes);
-            e2Attributes = Object.keys(e2.attributes);
+            var t = this;
+            // pre-diff hook
+            var info = {
+                diff: diff,
+                node: node
+            };
 
-            if (e1Attributes.length !== e2Attributes.length) {
-                return false;
+            if (this.preDiffApply(info)) {
+                return true;
             }
-            if (!e1Attributes.every(function(attribute) {
-                    if (e1.attributes[attribute] !== e2.attributes[attribute]) {
+
+            switch (diff[this._const.action]) {
+                case this._const.addAttribute:
+                    if (!node || !node.setAttribute) {
                         return false;
                     }
-                })) {
-                return false;
-            }
-        }
-
-        if (e1.childNodes) {
-            if (e1.childNodes.length !== e2.childNodes.length) {
-                return false;
+                    node.setAttribute(diff[this._const.name], diff[this._const.value]);
+                    break;
+                case this._const.modifyAttribute:
+                    if (!node || !node.setAttribute) {
+                        return false;
+                    }
+                    node.setAttribute(diff[this._const.name], diff[this._const.newValue]);
+                    break;
+                case this._const.removeAttribute:
+                    if (!node || !node.removeAttribute) {
+                        return false;
+                    }
+                    node.removeAttribute(diff[this._const.name]);
+                    break;
+                case this._const.modifyTextElement:
+                    if (!node || node.nodeType !== 3) {
+                        return false;
+                    }
+                    this.textDiff(node, node.data, diff[this._const.oldValue], diff[this._const.newValue]);
+                    break;
+                case this._const.modifyValue:
+                    if (!node || typeof node.value === 'undefined') {
+                        return false;
+                    }
+                    node.value = diff[this._const.newValue];
+                    break;
+                case this._const.modifyComment:
+                    if (!node || typeof node.data === 'undefined') {
+                        return false;
+                    }
+                    this.textDiff(node, node.data, diff[this._const.oldValue], diff[this._const.newValue]);
+                    break;
+                case this._const.modifyChecked:
+                    if (!node || typeof node.checked === 'undefined') {
+                        return false;
+                    }
+                    node.checked = diff[this._const.newValue];
+                    break;
+                case this._const.modifySelected:
+                    if (!node || typeof node.selected === 'undefined') {
+                        return false;
+                    }
+                    node.selected = diff[this._const.newValue];
+                    break;
+                case this._const.replaceElement:
+                    node.parentNode.replaceChild(this.objToNode(diff[this._const.newValue], node.namespaceURI === 'http://www.w3.org/2000/svg'), node);
+                    break;
+                case this._const.relocateGroup:
+                    nodeArray = Array.apply(null, new Array(diff.groupLength)).map(function() {
+                        return node.removeChild(node.childNodes[diff[t._const.from]]);
+                    });
+                    length = nodeArray.length;
+                    for (index = 0; index < length; index++) {
+                        childNode = nodeArray[index];
+                        if (index === 0) {
+                            reference = node.childNodes[diff[t._const.to]];
+                        }
+                        node.insertBefore(childNode, reference);
+                    }
+                    break;
+                case this._const.removeElement:
+                    this.scrollToMiddle(node);
+                    node.parentNode.removeChild(node);
+                    break;
+                case this._const.addElement:
+                    route = diff[this._const.route].slice();
+                    c = route.splice(route.length - 1, 1)[0];
+                    newNode = this.objToNode(diff[this._const.element], node.namespaceURI === 'http://www.w3.org/2000/svg');
+                    node = this.getFromRoute(tree, route);
+                    node.insertBefore(newNode, node.childNodes[c]);
+                    break;
+                case this._const.removeTextElement:
+                    if (!node || node.nodeType !== 3) {
+                        return false;
+                    }
+                    node.parentNode.removeChild(node);
+                    break;
+                case this._const.addTextElement:
+                    route = diff[this._const.route].slice();
+                    c = route.splice(route.length - 1, 1)[0];
+                    newNode = document.createTextNode(diff[this._const.value]);
+                    node = this.getFromRoute(tree, route);
+                    if (!node || !node.childNodes) {
+                        return false;
+                    }
+                    node.insertBefore(newNode, node.childNodes[c]);
+                    break;
+                default:
+                    console.log('unknown action');
             }
-            if (!e1.childNodes.every(function(childNode, index) {
-                    return isEqual(childNode, e2.childNodes[index]);
-                })) {
 
-                return false;
+            // if a new node was created, we might be interested in it
+            // post diff hook
+            info.newNode = newNode;
+            this.postDiffApply(info);
+
+            // console.warn(diff[this._const.action]);
+            if (diff[this._const.action] != this._const.removeElement) {
+                if (newNode && newNode.scrollIntoView) {
+                    this.scrollToMiddle(newNode);
+                } else if (node && node.scrollIntoView) {
+                    this.scrollToMiddle(node);
+                } else if (node.parentNode && node.parentNode.scrollIntoView) {
+                    this.scrollToMiddle(node.parentNode);
+                }
             }
 
-        }
+            return true;
+        },
 
-        return true;
+        scrollToMiddle: function(element) {
+            var elementRect = element.getBoundingClientRect();
+            var absoluteElementTop = elementRect.top + window.pageYOffset;
+            var middle = absoluteElementTop - (window.innerHeight / 2);
+            window.scrollTo(0, middle);
+        },
 
-    };
+        // ===== Undo a diff =====
 
+        undo: function(tree, diffs) {
+            var dobj = this, diff, length = diffs.length, i;
+            diffs = diffs.slice();
+            if (!length) {
+                diffs = [diffs];
+            }
+            diffs.reverse();
+            for (i = 0; i < length; i++) {
+                diff = diffs[i];
+                dobj.undoDiff(tree, diff);
+            }
+        },
+        undoDiff: function(tree, diff) {
 
-    var roughlyEqual = function(e1, e2, uniqueDescriptors, sameSiblings, preventRecursion) {
-        var childUniqueDescriptors, nodeList1, nodeList2;
+            switch (diff[this._const.action]) {
+                case this._const.addAttribute:
+                    diff[this._const.action] = this._const.removeAttribute;
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.modifyAttribute:
+                    swap(diff, this._const.oldValue, this._const.newValue);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.removeAttribute:
+                    diff[this._const.action] = this._const.addAttribute;
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.modifyTextElement:
+                    swap(diff, this._const.oldValue, this._const.newValue);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.modifyValue:
+                    swap(diff, this._const.oldValue, this._const.newValue);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.modifyComment:
+                    swap(diff, this._const.oldValue, this._const.newValue);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.modifyChecked:
+                    swap(diff, this._const.oldValue, this._const.newValue);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.modifySelected:
+                    swap(diff, this._const.oldValue, this._const.newValue);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.replaceElement:
+                    swap(diff, this._const.oldValue, this._const.newValue);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.relocateGroup:
+                    swap(diff, this._const.from, this._const.to);
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.removeElement:
+                    diff[this._const.action] = this._const.addElement;
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.addElement:
+                    diff[this._const.action] = this._const.removeElement;
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.removeTextElement:
+                    diff[this._const.action] = this._const.addTextElement;
+                    this.applyDiff(tree, diff);
+                    break;
+                case this._const.addTextElement:
+                    diff[this._const.action] = this._const.removeTextElement;
+                    this.applyDiff(tree, diff);
+                    break;
+                default:
+                    console.log('unknown action');
+            }
 
-        if (!e1 || !e2) {
-            return false;
         }
+    };
 
-        if (e1.nodeName !== e2.nodeName) {
-            return false;
-        }
+    return diffDOM;
+});
+`
+	html := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+  <head>
+<script type="text/javascript" src="qrc:///qtwebchannel/qwebchannel.js"></script>
+<script>%s</script>
+    <style>
+     %s
+    </style>
+    %s
+  </head>
+  <body>
+  %s
+  <script>
+  %s
+  </script>
+  <script>
+  if (%t) { scrollToLine(%d, %t); }
+  </script>
+  </body>
+</html>
+`, morphdomjs, markdownStyle, mathHead(false), content, js,
+		editor.config.Markdown.SyncScroll && m.cursorLine > 0,
+		m.cursorLine,
+		editor.config.Markdown.HighlightSyncedBlock,
+	)
 
-        if (e1.nodeName === '#text') {
-            // Note that we initially don't care what the text content of a node is,
-            // the mere fact that it's the same tag and "has text" means it's roughly
-            // equal, and then we can find out the true text difference later.
-            return preventRecursion ? true : e1.data === e2.data;
-        }
+	return html
+}
 
+// markdownStyle is the stylesheet shared by the preview and exported documents
+const markdownStyle = `
+.markdown-body .gonvim-preview-image {
+  text-align: center;
+}
 
-        if (e1.nodeName in uniqueDescriptors) {
-            return true;
-        }
+.markdown-body .gonvim-preview-image svg {
+  max-width: 100%;
+  height: auto;
+}
 
-        if (e1.attributes && e2.attributes) {
+.markdown-body .gonvim-preview-error {
+  color: #cb2431;
+  white-space: pre-wrap;
+}
 
-            if (e1.attributes.id) {
-                if (e1.attributes.id !== e2.attributes.id) {
-                    return false;
-                } else {
-                    var idDescriptor = e1.nodeName + '#' + e1.attributes.id;
-                    if (idDescriptor in uniqueDescriptors) {
-                        return true;
-                    }
-                }
-            }
-            if (e1.attributes['class'] && e1.attributes['class'] === e2.attributes['class']) {
-                var classDescriptor = e1.nodeName + '.' + e1.attributes['class'].replace(/ /g, '.');
-                if (classDescriptor in uniqueDescriptors) {
-                    return true;
-                }
-            }
-        }
-
-        if (sameSiblings) {
-            return true;
-        }
+.markdown-body .footnotes {
+  font-size: 85%;
+  color: #6a737d;
+  border-top: 1px solid #eaecef;
+}
 
-        nodeList1 = e1.childNodes ? e1.childNodes.slice().reverse() : [];
-        nodeList2 = e2.childNodes ? e2.childNodes.slice().reverse() : [];
+.markdown-body .footnotes ol {
+  padding-left: 16px;
+}
 
-        if (nodeList1.length !== nodeList2.length) {
-            return false;
-        }
+.markdown-body .math.display {
+  display: block;
+  overflow-x: auto;
+  text-align: center;
+}
 
-        if (preventRecursion) {
-            return nodeList1.every(function(element, index) {
-                return element.nodeName === nodeList2[index].nodeName;
-            });
-        } else {
-            // note: we only allow one level of recursion at any depth. If 'preventRecursion'
-            // was not set, we must explicitly force it to true for child iterations.
-            childUniqueDescriptors = uniqueInBoth(nodeList1, nodeList2);
-            return nodeList1.every(function(element, index) {
-                return roughlyEqual(element, nodeList2[index], childUniqueDescriptors, true, true);
-            });
-        }
-    };
+.markdown-body .gonvim-cursor-block {
+  background-color: rgba(255, 221, 0, 0.15);
+  box-shadow: -8px 0 0 0 rgba(255, 221, 0, 0.6);
+}
 
+.markdown-body {
+  -ms-text-size-adjust: 100%;
+  -webkit-text-size-adjust: 100%;
+  line-height: 1.5;
+  color: #24292e;
+  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif, "Apple Color Emoji", "Segoe UI Emoji", "Segoe UI Symbol";
+  font-size: 16px;
+  line-height: 1.5;
+  word-wrap: break-word;
+}
 
-    var cloneObj = function(obj) {
-        //  TODO: Do we really need to clone here? Is it not enough to just return the original object?
-        return JSON.parse(JSON.stringify(obj));
-    };
+.markdown-body .pl-c {
+  color: #6a737d;
+}
 
-    /**
-     * based on https://en.wikibooks.org/wiki/Algorithm_implementation/Strings/Longest_common_substring#JavaScript
-     */
-    var findCommonSubsets = function(c1, c2, marked1, marked2) {
-        var lcsSize = 0,
-            index = [],
-            c1Length = c1.length,
-            c2Length = c2.length,
-            matches = Array.apply(null, new Array(c1Length + 1)).map(function() {
-                return [];
-            }), // set up the matching table
-            uniqueDescriptors = uniqueInBoth(c1, c2),
-            // If all of the elements are the same tag, id and class, then we can
-            // consider them roughly the same even if they have a different number of
-            // children. This will reduce removing and re-adding similar elements.
-            subsetsSame = c1Length === c2Length,
-            origin, ret, c1Index, c2Index, c1Element, c2Element;
+.markdown-body .pl-c1,
+.markdown-body .pl-s .pl-v {
+  color: #005cc5;
+}
 
-        if (subsetsSame) {
+.markdown-body .pl-e,
+.markdown-body .pl-en {
+  color: #6f42c1;
+}
 
-            c1.some(function(element, i) {
-                var c1Desc = elementDescriptors(element),
-                    c2Desc = elementDescriptors(c2[i]);
-                if (c1Desc.length !== c2Desc.length) {
-                    subsetsSame = false;
-                    return true;
-                }
-                c1Desc.some(function(description, i) {
-                    if (description !== c2Desc[i]) {
-                        subsetsSame = false;
-                        return true;
-                    }
-                });
-                if (!subsetsSame) {
-                    return true;
-                }
+.markdown-body .pl-smi,
+.markdown-body .pl-s .pl-s1 {
+  color: #24292e;
+}
 
-            });
-        }
+.markdown-body .pl-ent {
+  color: #22863a;
+}
 
-        // fill the matches with distance values
-        for (c1Index = 0; c1Index < c1Length; c1Index++) {
-            c1Element = c1[c1Index];
-            for (c2Index = 0; c2Index < c2Length; c2Index++) {
-                c2Element = c2[c2Index];
-                if (!marked1[c1Index] && !marked2[c2Index] && roughlyEqual(c1Element, c2Element, uniqueDescriptors, subsetsSame)) {
-                    matches[c1Index + 1][c2Index + 1] = (matches[c1Index][c2Index] ? matches[c1Index][c2Index] + 1 : 1);
-                    if (matches[c1Index + 1][c2Index + 1] >= lcsSize) {
-                        lcsSize = matches[c1Index + 1][c2Index + 1];
-                        index = [c1Index + 1, c2Index + 1];
-                    }
-                } else {
-                    matches[c1Index + 1][c2Index + 1] = 0;
-                }
-            }
-        }
+.markdown-body .pl-k {
+  color: #d73a49;
+}
 
-        if (lcsSize === 0) {
-            return false;
-        }
-        origin = [index[0] - lcsSize, index[1] - lcsSize];
-        ret = new SubsetMapping(origin[0], origin[1]);
-        ret.length = lcsSize;
+.markdown-body .pl-s,
+.markdown-body .pl-pds,
+.markdown-body .pl-s .pl-pse .pl-s1,
+.markdown-body .pl-sr,
+.markdown-body .pl-sr .pl-cce,
+.markdown-body .pl-sr .pl-sre,
+.markdown-body .pl-sr .pl-sra {
+  color: #032f62;
+}
 
-        return ret;
-    };
+.markdown-body .pl-v,
+.markdown-body .pl-smw {
+  color: #e36209;
+}
 
-    /**
-     * This should really be a predefined function in Array...
-     */
-    var makeArray = function(n, v) {
-        return Array.apply(null, new Array(n)).map(function() {
-            return v;
-        });
-    };
+.markdown-body .pl-bu {
+  color: #b31d28;
+}
 
-    /**
-     * Generate arrays that indicate which node belongs to which subset,
-     * or whether it's actually an orphan node, existing in only one
-     * of the two trees, rather than somewhere in both.
-     *
-     * So if t1 = <img><canvas><br>, t2 = <canvas><br><img>.
-     * The longest subset is "<canvas><br>" (length 2), so it will group 0.
-     * The second longest is "<img>" (length 1), so it will be group 1.
-     * gaps1 will therefore be [1,0,0] and gaps2 [0,0,1].
-     *
-     * If an element is not part of any group, it will stay being 'true', which
-     * is the initial value. For example:
-     * t1 = <img><p></p><br><canvas>, t2 = <b></b><br><canvas><img>
-     *
-     * The "<p></p>" and "<b></b>" do only show up in one of the two and will
-     * therefore be marked by "true". The remaining parts are parts of the
-     * groups 0 and 1:
-     * gaps1 = [1, true, 0, 0], gaps2 = [true, 0, 0, 1]
-     *
-     */
-    var getGapInformation = function(t1, t2, stable) {
+.markdown-body .pl-ii {
+  color: #fafbfc;
+  background-color: #b31d28;
+}
 
-        var gaps1 = t1.childNodes ? makeArray(t1.childNodes.length, true) : [],
-            gaps2 = t2.childNodes ? makeArray(t2.childNodes.length, true) : [],
-            group = 0,
-            length = stable.length,
-            i, j, endOld, endNew, subset;
+.markdown-body .pl-c2 {
+  color: #fafbfc;
+  background-color: #d73a49;
+}
 
-        // give elements from the same subset the same group number
-        for (i = 0; i < length; i++) {
-            subset = stable[i];
-            endOld = subset.oldValue + subset.length;
-            endNew = subset.newValue + subset.length;
-            for (j = subset.oldValue; j < endOld; j += 1) {
-                gaps1[j] = group;
-            }
-            for (j = subset.newValue; j < endNew; j += 1) {
-                gaps2[j] = group;
-            }
-            group += 1;
-        }
+.markdown-body .pl-c2::before {
+  content: "^M";
+}
 
-        return {
-            gaps1: gaps1,
-            gaps2: gaps2
-        };
-    };
+.markdown-body .pl-sr .pl-cce {
+  font-weight: bold;
+  color: #22863a;
+}
 
-    /**
-     * Find all matching subsets, based on immediate child differences only.
-     */
-    var markSubTrees = function(oldTree, newTree) {
-        // note: the child lists are views, and so update as we update old/newTree
-        var oldChildren = oldTree.childNodes ? oldTree.childNodes : [],
-            newChildren = newTree.childNodes ? newTree.childNodes : [],
-            marked1 = makeArray(oldChildren.length, false),
-            marked2 = makeArray(newChildren.length, false),
-            subsets = [],
-            subset = true,
-            returnIndex = function() {
-                return arguments[1];
-            },
-            markBoth = function(i) {
-                marked1[subset.oldValue + i] = true;
-                marked2[subset.newValue + i] = true;
-            },
-            length, subsetArray, i;
+.markdown-body .pl-ml {
+  color: #735c0f;
+}
 
-        while (subset) {
-            subset = findCommonSubsets(oldChildren, newChildren, marked1, marked2);
-            if (subset) {
-                subsets.push(subset);
-                subsetArray = Array.apply(null, new Array(subset.length)).map(returnIndex);
-                length = subsetArray.length;
-                for (i = 0; i < length; i++) {
-                    markBoth(subsetArray[i]);
-                }
-            }
-        }
-        return subsets;
-    };
+.markdown-body .pl-mh,
+.markdown-body .pl-mh .pl-en,
+.markdown-body .pl-ms {
+  font-weight: bold;
+  color: #005cc5;
+}
 
+.markdown-body .pl-mi {
+  font-style: italic;
+  color: #24292e;
+}
 
-    function swap(obj, p1, p2) {
-        var tmp = obj[p1];
-        obj[p1] = obj[p2];
-        obj[p2] = tmp;
-    }
-
+.markdown-body .pl-mb {
+  font-weight: bold;
+  color: #24292e;
+}
 
-    var DiffTracker = function() {
-        this.list = [];
-    };
+.markdown-body .pl-md {
+  color: #b31d28;
+  background-color: #ffeef0;
+}
 
-    DiffTracker.prototype = {
-        list: false,
-        add: function(diffs) {
-            this.list.push.apply(this.list, diffs);
-        },
-        forEach: function(fn) {
-            var length = this.list.length,
-                i;
-            for (i = 0; i < length; i++) {
-                fn(this.list[i]);
-            }
-        }
-    };
+.markdown-body .pl-mi1 {
+  color: #22863a;
+  background-color: #f0fff4;
+}
 
-    var diffDOM = function(options) {
+.markdown-body .pl-mc {
+  color: #e36209;
+  background-color: #ffebda;
+}
 
-        var defaults = {
-                debug: false,
-                diffcap: 10, // Limit for how many diffs are accepting when debugging. Inactive when debug is false.
-                maxDepth: false, // False or a numeral. If set to a numeral, limits the level of depth that the the diff mechanism looks for differences. If false, goes through the entire tree.
-                valueDiffing: true, // Whether to take into consideration the values of forms that differ from auto assigned values (when a user fills out a form).
-                // syntax: textDiff: function (node, currentValue, expectedValue, newValue)
-                textDiff: function() {
-                    arguments[0].data = arguments[3];
-                    return;
-                },
-                // empty functions were benchmarked as running faster than both
-                // f && f() and if (f) { f(); }
-                preVirtualDiffApply: function() {},
-                postVirtualDiffApply: function() {},
-                preDiffApply: function() {},
-                postDiffApply: function() {},
-                filterOuterDiff: null,
-                compress: false // Whether to work with compressed diffs
-            },
-            varNames, i, j;
+.markdown-body .pl-mi2 {
+  color: #f6f8fa;
+  background-color: #005cc5;
+}
 
-        if (typeof options === "undefined") {
-            options = {};
-        }
+.markdown-body .pl-mdr {
+  font-weight: bold;
+  color: #6f42c1;
+}
 
-        for (i in defaults) {
-            if (typeof options[i] === "undefined") {
-                this[i] = defaults[i];
-            } else {
-                this[i] = options[i];
-            }
-        }
+.markdown-body .pl-ba {
+  color: #586069;
+}
 
-        var varNames = {
-            'addAttribute': 'addAttribute',
-            'modifyAttribute': 'modifyAttribute',
-            'removeAttribute': 'removeAttribute',
-            'modifyTextElement': 'modifyTextElement',
-            'relocateGroup': 'relocateGroup',
-            'removeElement': 'removeElement',
-            'addElement': 'addElement',
-            'removeTextElement': 'removeTextElement',
-            'addTextElement': 'addTextElement',
-            'replaceElement': 'replaceElement',
-            'modifyValue': 'modifyValue',
-            'modifyChecked': 'modifyChecked',
-            'modifySelected': 'modifySelected',
-            'modifyComment': 'modifyComment',
-            'action': 'action',
-            'route': 'route',
-            'oldValue': 'oldValue',
-            'newValue': 'newValue',
-            'element': 'element',
-            'group': 'group',
-            'from': 'from',
-            'to': 'to',
-            'name': 'name',
-            'value': 'value',
-            'data': 'data',
-            'attributes': 'attributes',
-            'nodeName': 'nodeName',
-            'childNodes': 'childNodes',
-            'checked': 'checked',
-            'selected': 'selected'
-        };
+.markdown-body .pl-sg {
+  color: #959da5;
+}
 
-        if (this.compress) {
-            j = 0;
-            this._const = {};
-            for (i in varNames) {
-                this._const[i] = j;
-                j++;
-            }
-        } else {
-            this._const = varNames;
-        }
-    };
+.markdown-body .pl-corl {
+  text-decoration: underline;
+  color: #032f62;
+}
 
-    diffDOM.Diff = Diff;
+.markdown-body .octicon {
+  display: inline-block;
+  vertical-align: text-top;
+  fill: currentColor;
+}
 
-    diffDOM.prototype = {
+.markdown-body a {
+  background-color: transparent;
+}
 
-        // ===== Create a diff =====
+.markdown-body a:active,
+.markdown-body a:hover {
+  outline-width: 0;
+}
 
-        diff: function(t1Node, t2Node) {
+.markdown-body strong {
+  font-weight: inherit;
+}
 
-            var t1 = this.nodeToObj(t1Node),
-                t2 = this.nodeToObj(t2Node);
+.markdown-body strong {
+  font-weight: bolder;
+}
 
-            diffcount = 0;
+.markdown-body h1 {
+  font-size: 2em;
+  margin: 0.67em 0;
+}
 
-            if (this.debug) {
-                this.t1Orig = this.nodeToObj(t1Node);
-                this.t2Orig = this.nodeToObj(t2Node);
-            }
+.markdown-body img {
+  border-style: none;
+}
 
-            this.tracker = new DiffTracker();
-            return this.findDiffs(t1, t2);
-        },
-        findDiffs: function(t1, t2) {
-            var diffs;
-            do {
-                if (this.debug) {
-                    diffcount += 1;
-                    if (diffcount > this.diffcap) {
-                        window.diffError = [this.t1Orig, this.t2Orig];
-                        throw new Error("surpassed diffcap:" + JSON.stringify(this.t1Orig) + " -> " + JSON.stringify(this.t2Orig));
-                    }
-                }
-                diffs = this.findNextDiff(t1, t2, []);
-                if (diffs.length === 0) {
-                    // Last check if the elements really are the same now.
-                    // If not, remove all info about being done and start over.
-                    // Sometimes a node can be marked as done, but the creation of subsequent diffs means that it has to be changed anyway.
-                    if (!isEqual(t1, t2)) {
-                        removeDone(t1);
-                        diffs = this.findNextDiff(t1, t2, []);
-                    }
-                }
+.markdown-body code,
+.markdown-body kbd,
+.markdown-body pre {
+  font-family: monospace, monospace;
+  font-size: 1em;
+}
 
-                if (diffs.length > 0) {
-                    this.tracker.add(diffs);
-                    this.applyVirtual(t1, diffs);
-                }
-            } while (diffs.length > 0);
-            return this.tracker.list;
-        },
-        findNextDiff: function(t1, t2, route) {
-            var diffs, fdiffs;
+.markdown-body hr {
+  box-sizing: content-box;
+  height: 0;
+  overflow: visible;
+}
 
-            if (this.maxDepth && route.length > this.maxDepth) {
-                return [];
-            }
-            // outer differences?
-            if (!t1.outerDone) {
-                diffs = this.findOuterDiff(t1, t2, route);
-                if (this.filterOuterDiff) {
-                    fdiffs = this.filterOuterDiff(t1, t2, diffs);
-                    if (fdiffs) diffs = fdiffs;
-                }
-                if (diffs.length > 0) {
-                    t1.outerDone = true;
-                    return diffs;
-                } else {
-                    t1.outerDone = true;
-                }
-            }
-            // inner differences?
-            if (!t1.innerDone) {
-                diffs = this.findInnerDiff(t1, t2, route);
-                if (diffs.length > 0) {
-                    return diffs;
-                } else {
-                    t1.innerDone = true;
-                }
-            }
+.markdown-body input {
+  font: inherit;
+  margin: 0;
+}
 
-            if (this.valueDiffing && !t1.valueDone) {
-                // value differences?
-                diffs = this.findValueDiff(t1, t2, route);
+.markdown-body input {
+  overflow: visible;
+}
 
-                if (diffs.length > 0) {
-                    t1.valueDone = true;
-                    return diffs;
-                } else {
-                    t1.valueDone = true;
-                }
-            }
+.markdown-body [type="checkbox"] {
+  box-sizing: border-box;
+  padding: 0;
+}
 
-            // no differences
-            return [];
-        },
-        findOuterDiff: function(t1, t2, route) {
-            var t = this;
-            var diffs = [],
-                attr,
-                attr1, attr2, attrLength, pos, i;
-
-            if (t1.nodeName !== t2.nodeName) {
-                return [new Diff()
-                    .setValue(t._const.action, t._const.replaceElement)
-                    .setValue(t._const.oldValue, cloneObj(t1))
-                    .setValue(t._const.newValue, cloneObj(t2))
-                    .setValue(t._const.route, route)
-                ];
-            }
+.markdown-body * {
+  box-sizing: border-box;
+}
 
-            if (t1.data !== t2.data) {
-                // Comment or text node.
-                if (t1.nodeName === '#text') {
-                    return [new Diff()
-                        .setValue(t._const.action, t._const.modifyTextElement)
-                        .setValue(t._const.route, route)
-                        .setValue(t._const.oldValue, t1.data)
-                        .setValue(t._const.newValue, t2.data)
-                    ];
-                } else {
-                    return [new Diff()
-                        .setValue(t._const.action, t._const.modifyComment)
-                        .setValue(t._const.route, route)
-                        .setValue(t._const.oldValue, t1.data)
-                        .setValue(t._const.newValue, t2.data)
-                    ];
-                }
+.markdown-body input {
+  font-family: inherit;
+  font-size: inherit;
+  line-height: inherit;
+}
 
-            }
+.markdown-body a {
+  color: #0366d6;
+  text-decoration: none;
+}
 
+.markdown-body a:hover {
+  text-decoration: underline;
+}
 
-            attr1 = t1.attributes ? Object.keys(t1.attributes).sort() : [];
-            attr2 = t2.attributes ? Object.keys(t2.attributes).sort() : [];
+.markdown-body strong {
+  font-weight: 600;
+}
 
-            attrLength = attr1.length;
-            for (i = 0; i < attrLength; i++) {
-                attr = attr1[i];
-                pos = attr2.indexOf(attr);
-                if (pos === -1) {
-                    diffs.push(new Diff()
-                        .setValue(t._const.action, t._const.removeAttribute)
-                        .setValue(t._const.route, route)
-                        .setValue(t._const.name, attr)
-                        .setValue(t._const.value, t1.attributes[attr])
-                    );
-                } else {
-                    attr2.splice(pos, 1);
-                    if (t1.attributes[attr] !== t2.attributes[attr]) {
-                        diffs.push(new Diff()
-                            .setValue(t._const.action, t._const.modifyAttribute)
-                            .setValue(t._const.route, route)
-                            .setValue(t._const.name, attr)
-                            .setValue(t._const.oldValue, t1.attributes[attr])
-                            .setValue(t._const.newValue, t2.attributes[attr])
-                        );
-                    }
-                }
-            }
+.markdown-body hr {
+  height: 0;
+  margin: 15px 0;
+  overflow: hidden;
+  background: transparent;
+  border: 0;
+  border-bottom: 1px solid #dfe2e5;
+}
 
-            attrLength = attr2.length;
-            for (i = 0; i < attrLength; i++) {
-                attr = attr2[i];
-                diffs.push(new Diff()
-                    .setValue(t._const.action, t._const.addAttribute)
-                    .setValue(t._const.route, route)
-                    .setValue(t._const.name, attr)
-                    .setValue(t._const.value, t2.attributes[attr])
-                );
-            }
+.markdown-body hr::before {
+  display: table;
+  content: "";
+}
 
-            return diffs;
-        },
-        nodeToObj: function(aNode) {
-            var objNode = {},
-                dobj = this,
-                nodeArray, childNode, length, attribute, i;
-            objNode.nodeName = aNode.nodeName;
-            if (objNode.nodeName === '#text' || objNode.nodeName === '#comment') {
-                objNode.data = aNode.data;
-            } else {
-                if (aNode.attributes && aNode.attributes.length > 0) {
-                    objNode.attributes = {};
-                    nodeArray = Array.prototype.slice.call(aNode.attributes);
-                    length = nodeArray.length;
-                    for (i = 0; i < length; i++) {
-                        attribute = nodeArray[i];
-                        objNode.attributes[attribute.name] = attribute.value;
-                    }
-                }
-                if (objNode.nodeName === 'TEXTAREA') {
-                    objNode.value = aNode.value;
-                } else if (aNode.childNodes && aNode.childNodes.length > 0) {
-                    objNode.childNodes = [];
-                    nodeArray = Array.prototype.slice.call(aNode.childNodes);
-                    length = nodeArray.length;
-                    for (i = 0; i < length; i++) {
-                        childNode = nodeArray[i];
-                        objNode.childNodes.push(dobj.nodeToObj(childNode));
-                    }
-                }
-                if (this.valueDiffing) {
-                    if (aNode.checked !== undefined && aNode.type &&
-                        ['radio','checkbox'].indexOf(aNode.type.toLowerCase()) !== -1
-                    ) {
-                        objNode.checked = aNode.checked;
-                    } else if (aNode.value !== undefined) {
-                        objNode.value = aNode.value;
-                    }
-                    if (aNode.selected !== undefined) {
-                        objNode.selected = aNode.selected;
-                    }
-                }
-            }
-            return objNode;
-        },
-        objToNode: function(objNode, insideSvg) {
-            var node, dobj = this,
-                attribute, attributeArray, childNode, childNodeArray, length, i;
-            if (objNode.nodeName === '#text') {
-                node = document.createTextNode(objNode.data);
+.markdown-body hr::after {
+  display: table;
+  clear: both;
+  content: "";
+}
 
-            } else if (objNode.nodeName === '#comment') {
-                node = document.createComment(objNode.data);
-            } else {
-                if (objNode.nodeName === 'svg' || insideSvg) {
-                    node = document.createElementNS('http://www.w3.org/2000/svg', objNode.nodeName);
-                    insideSvg = true;
-                } else {
-                    node = document.createElement(objNode.nodeName);
-                }
-                if (objNode.attributes) {
-                    attributeArray = Object.keys(objNode.attributes);
-                    length = attributeArray.length;
-                    for (i = 0; i < length; i++) {
-                        attribute = attributeArray[i];
-                        node.setAttribute(attribute, objNode.attributes[attribute]);
-                    }
-                }
-                if (objNode.childNodes) {
-                    childNodeArray = objNode.childNodes;
-                    length = childNodeArray.length;
-                    for (i = 0; i < length; i++) {
-                        childNode = childNodeArray[i];
-                        node.appendChild(dobj.objToNode(childNode, insideSvg));
-                    }
-                }
-                if (this.valueDiffing) {
-                    if (objNode.value) {
-                        node.value = objNode.value;
-                    }
-                    if (objNode.checked) {
-                        node.checked = objNode.checked;
-                    }
-                    if (objNode.selected) {
-                        node.selected = objNode.selected;
-                    }
-                }
-            }
-            return node;
-        },
-        findInnerDiff: function(t1, t2, route) {
-            var t = this;
-            var subtrees = (t1.childNodes && t2.childNodes) ? markSubTrees(t1, t2) : [],
-                t1ChildNodes = t1.childNodes ? t1.childNodes : [],
-                t2ChildNodes = t2.childNodes ? t2.childNodes : [],
-                childNodesLengthDifference, diffs = [],
-                index = 0,
-                last, e1, e2, i;
+.markdown-body table {
+  border-spacing: 0;
+  border-collapse: collapse;
+}
 
-            if (subtrees.length > 0) {
-                /* One or more groups have been identified among the childnodes of t1
-                 * and t2.
-                 */
-                diffs = this.attemptGroupRelocation(t1, t2, subtrees, route);
-                if (diffs.length > 0) {
-                    return diffs;
-                }
-            }
+.markdown-body td,
+.markdown-body th {
+  padding: 0;
+}
 
-            /* 0 or 1 groups of similar child nodes have been found
-             * for t1 and t2. 1 If there is 1, it could be a sign that the
-             * contents are the same. When the number of groups is below 2,
-             * t1 and t2 are made to have the same length and each of the
-             * pairs of child nodes are diffed.
-             */
+.markdown-body h1,
+.markdown-body h2,
+.markdown-body h3,
+.markdown-body h4,
+.markdown-body h5,
+.markdown-body h6 {
+  margin-top: 0;
+  margin-bottom: 0;
+}
 
+.markdown-body h1 {
+  font-size: 32px;
+  font-weight: 600;
+}
 
-            last = Math.max(t1ChildNodes.length, t2ChildNodes.length);
-            if (t1ChildNodes.length !== t2ChildNodes.length) {
-                childNodesLengthDifference = true;
-            }
+.markdown-body h2 {
+  font-size: 24px;
+  font-weight: 600;
+}
 
-            for (i = 0; i < last; i += 1) {
-                e1 = t1ChildNodes[i];
-                e2 = t2ChildNodes[i];
+.markdown-body h3 {
+  font-size: 20px;
+  font-weight: 600;
+}
 
-                if (childNodesLengthDifference) {
-                    /* t1 and t2 have different amounts of childNodes. Add
-                     * and remove as necessary to obtain the same length */
-                    if (e1 && !e2) {
-                        if (e1.nodeName === '#text') {
-                            diffs.push(new Diff()
-                                .setValue(t._const.action, t._const.removeTextElement)
-                                .setValue(t._const.route, route.concat(index))
-                                .setValue(t._const.value, e1.data)
-                            );
-                            index -= 1;
-                        } else {
-                            diffs.push(new Diff()
-                                .setValue(t._const.action, t._const.removeElement)
-                                .setValue(t._const.route, route.concat(index))
-                                .setValue(t._const.element, cloneObj(e1))
-                            );
-                            index -= 1;
-                        }
+.markdown-body h4 {
+  font-size: 16px;
+  font-weight: 600;
+}
 
-                    } else if (e2 && !e1) {
-                        if (e2.nodeName === '#text') {
-                            diffs.push(new Diff()
-                                .setValue(t._const.action, t._const.addTextElement)
-                                .setValue(t._const.route, route.concat(index))
-                                .setValue(t._const.value, e2.data)
-                            );
-                        } else {
-                            diffs.push(new Diff()
-                                .setValue(t._const.action, t._const.addElement)
-                                .setValue(t._const.route, route.concat(index))
-                                .setValue(t._const.element, cloneObj(e2))
-                            );
-                        }
-                    }
-                }
-                /* We are now guaranteed that childNodes e1 and e2 exist,
-                 * and that they can be diffed.
-                 */
-                /* Diffs in child nodes should not affect the parent node,
-                 * so we let these diffs be submitted together with other
-                 * diffs.
-                 */
+.markdown-body h5 {
+  font-size: 14px;
+  font-weight: 600;
+}
 
-                if (e1 && e2) {
-                    diffs = diffs.concat(this.findNextDiff(e1, e2, route.concat(index)));
-                }
+.markdown-body h6 {
+  font-size: 12px;
+  font-weight: 600;
+}
 
-                index += 1;
+.markdown-body p {
+  margin-top: 0;
+  margin-bottom: 10px;
+}
 
-            }
-            t1.innerDone = true;
-            return diffs;
+.markdown-body blockquote {
+  margin: 0;
+}
 
-        },
+.markdown-body ul,
+.markdown-body ol {
+  padding-left: 0;
+  margin-top: 0;
+  margin-bottom: 0;
+}
 
-        attemptGroupRelocation: function(t1, t2, subtrees, route) {
-            /* Either t1.childNodes and t2.childNodes have the same length, or
-             * there are at least two groups of similar elements can be found.
-             * attempts are made at equalizing t1 with t2. First all initial
-             * elements with no group affiliation (gaps=true) are removed (if
-             * only in t1) or added (if only in t2). Then the creation of a group
-             * relocation diff is attempted.
-             */
-            var t = this;
-            var gapInformation = getGapInformation(t1, t2, subtrees),
-                gaps1 = gapInformation.gaps1,
-                gaps2 = gapInformation.gaps2,
-                shortest = Math.min(gaps1.length, gaps2.length),
-                destinationDifferent, toGroup,
-                group, node, similarNode, testI, diffs = [],
-                index1, index2, j;
+.markdown-body ol ol,
+.markdown-body ul ol {
+  list-style-type: lower-roman;
+}
 
+.markdown-body ul ul ol,
+.markdown-body ul ol ol,
+.markdown-body ol ul ol,
+.markdown-body ol ol ol {
+  list-style-type: lower-alpha;
+}
 
-            for (index2 = 0, index1 = 0; index2 < shortest; index1 += 1, index2 += 1) {
-                if (gaps1[index2] === true) {
-                    node = t1.childNodes[index1];
-                    if (node.nodeName === '#text') {
-                        if (t2.childNodes[index2].nodeName === '#text' && node.data !== t2.childNodes[index2].data) {
-                            testI = index1;
-                            while (t1.childNodes.length > testI + 1 && t1.childNodes[testI + 1].nodeName === '#text') {
-                                testI += 1;
-                                if (t2.childNodes[index2].data === t1.childNodes[testI].data) {
-                                    similarNode = true;
-                                    break;
-                                }
-                            }
-                            if (!similarNode) {
-                                diffs.push(new Diff()
-                                    .setValue(t._const.action, t._const.modifyTextElement)
-                                    .setValue(t._const.route, route.concat(index2))
-                                    .setValue(t._const.oldValue, node.data)
-                                    .setValue(t._const.newValue, t2.childNodes[index2].data)
-                                );
-                                return diffs;
-                            }
-                        }
-                        diffs.push(new Diff()
-                            .setValue(t._const.action, t._const.removeTextElement)
-                            .setValue(t._const.route, route.concat(index2))
-                            .setValue(t._const.value, node.data)
-                        );
-                        gaps1.splice(index2, 1);
-                        shortest = Math.min(gaps1.length, gaps2.length);
-                        index2 -= 1;
-                    } else {
-                        diffs.push(new Diff()
-                            .setValue(t._const.action, t._const.removeElement)
-                            .setValue(t._const.route, route.concat(index2))
-                            .setValue(t._const.element, cloneObj(node))
-                        );
-                        gaps1.splice(index2, 1);
-                        shortest = Math.min(gaps1.length, gaps2.length);
-                        index2 -= 1;
-                    }
+.markdown-body dd {
+  margin-left: 0;
+}
 
-                } else if (gaps2[index2] === true) {
-                    node = t2.childNodes[index2];
-                    if (node.nodeName === '#text') {
-                        diffs.push(new Diff()
-                            .setValue(t._const.action, t._const.addTextElement)
-                            .setValue(t._const.route, route.concat(index2))
-                            .setValue(t._const.value, node.data)
-                        );
-                        gaps1.splice(index2, 0, true);
-                        shortest = Math.min(gaps1.length, gaps2.length);
-                        index1 -= 1;
-                    } else {
-                        diffs.push(new Diff()
-                            .setValue(t._const.action, t._const.addElement)
-                            .setValue(t._const.route, route.concat(index2))
-                            .setValue(t._const.element, cloneObj(node))
-                        );
-                        gaps1.splice(index2, 0, true);
-                        shortest = Math.min(gaps1.length, gaps2.length);
-                        index1 -= 1;
-                    }
+.markdown-body code {
+  font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, Courier, monospace;
+  font-size: 12px;
+}
 
-                } else if (gaps1[index2] !== gaps2[index2]) {
-                    if (diffs.length > 0) {
-                        return diffs;
-                    }
-                    // group relocation
-                    group = subtrees[gaps1[index2]];
-                    toGroup = Math.min(group.newValue, (t1.childNodes.length - group.length));
-                    if (toGroup !== group.oldValue) {
-                        // Check whether destination nodes are different than originating ones.
-                        destinationDifferent = false;
-                        for (j = 0; j < group.length; j += 1) {
-                            if (!roughlyEqual(t1.childNodes[toGroup + j], t1.childNodes[group.oldValue + j], [], false, true)) {
-                                destinationDifferent = true;
-                            }
-                        }
-                        if (destinationDifferent) {
-                            return [new Diff()
-                                .setValue(t._const.action, t._const.relocateGroup)
-                                .setValue('groupLength', group.length)
-                                .setValue(t._const.from, group.oldValue)
-                                .setValue(t._const.to, toGroup)
-                                .setValue(t._const.route, route)
-                            ];
-                        }
-                    }
-                }
-            }
-            return diffs;
-        },
+.markdown-body pre {
+  margin-top: 0;
+  margin-bottom: 0;
+  font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, Courier, monospace;
+  font-size: 12px;
+}
 
-        findValueDiff: function(t1, t2, route) {
-            // Differences of value. Only useful if the value/selection/checked value
-            // differs from what is represented in the DOM. For example in the case
-            // of filled out forms, etc.
-            var diffs = [];
-            var t = this;
+.markdown-body .octicon {
+  vertical-align: text-bottom;
+}
 
-            if (t1.selected !== t2.selected) {
-                diffs.push(new Diff()
-                    .setValue(t._const.action, t._const.modifySelected)
-                    .setValue(t._const.oldValue, t1.selected)
-                    .setValue(t._const.newValue, t2.selected)
-                    .setValue(t._const.route, route)
-                );
-            }
+.markdown-body .pl-0 {
+  padding-left: 0 !important;
+}
 
-            if ((t1.value || t2.value) && t1.value !== t2.value && t1.nodeName !== 'OPTION') {
-                diffs.push(new Diff()
-                    .setValue(t._const.action, t._const.modifyValue)
-                    .setValue(t._const.oldValue, t1.value)
-                    .setValue(t._const.newValue, t2.value)
-                    .setValue(t._const.route, route)
-                );
-            }
-            if (t1.checked !== t2.checked) {
-                diffs.push(new Diff()
-                    .setValue(t._const.action, t._const.modifyChecked)
-                    .setValue(t._const.oldValue, t1.checked)
-                    .setValue(t._const.newValue, t2.checked)
-                    .setValue(t._const.route, route)
-                );
-            }
+.markdown-body .pl-1 {
+  padding-left: 4px !important;
+}
 
-            return diffs;
-        },
+.markdown-body .pl-2 {
+  padding-left: 8px !important;
+}
 
-        // ===== Apply a virtual diff =====
+.markdown-body .pl-3 {
+  padding-left: 16px !important;
+}
 
-        applyVirtual: function(tree, diffs) {
-            var dobj = this,
-                length = diffs.length,
-                diff, i;
-            if (length === 0) {
-                return true;
-            }
-            for (i = 0; i < length; i++) {
-                diff = diffs[i];
-                dobj.applyVirtualDiff(tree, diff);
-            }
-            return true;
-        },
-        getFromVirtualRoute: function(tree, route) {
-            var node = tree,
-                parentNode, nodeIndex;
+.markdown-body .pl-4 {
+  padding-left: 24px !important;
+}
 
-            route = route.slice();
-            while (route.length > 0) {
-                if (!node.childNodes) {
-                    return false;
-                }
-                nodeIndex = route.splice(0, 1)[0];
-                parentNode = node;
-                node = node.childNodes[nodeIndex];
-            }
-            return {
-                node: node,
-                parentNode: parentNode,
-                nodeIndex: nodeIndex
-            };
-        },
-        applyVirtualDiff: function(tree, diff) {
-            var routeInfo = this.getFromVirtualRoute(tree, diff[this._const.route]),
-                node = routeInfo.node,
-                parentNode = routeInfo.parentNode,
-                nodeIndex = routeInfo.nodeIndex,
-                newNode, movedNode, nodeArray, route, length, c, i;
+.markdown-body .pl-5 {
+  padding-left: 32px !important;
+}
 
-            var t = this;
-            // pre-diff hook
-            var info = {
-                diff: diff,
-                node: node
-            };
+.markdown-body .pl-6 {
+  padding-left: 40px !important;
+}
 
-            if (this.preVirtualDiffApply(info)) {
-                return true;
-            }
+.markdown-body::before {
+  display: table;
+  content: "";
+}
 
-            switch (diff[this._const.action]) {
-                case this._const.addAttribute:
-                    if (!node.attributes) {
-                        node.attributes = {};
-                    }
+.markdown-body::after {
+  display: table;
+  clear: both;
+  content: "";
+}
 
-                    node.attributes[diff[this._const.name]] = diff[this._const.value];
+.markdown-body>*:first-child {
+  margin-top: 0 !important;
+}
 
-                    if (diff[this._const.name] === 'checked') {
-                        node.checked = true;
-                    } else if (diff[this._const.name] === 'selected') {
-                        node.selected = true;
-                    } else if (node.nodeName === 'INPUT' && diff[this._const.name] === 'value') {
-                        node.value = diff[this._const.value];
-                    }
+.markdown-body>*:last-child {
+  margin-bottom: 0 !important;
+}
 
-                    break;
-                case this._const.modifyAttribute:
-                    node.attributes[diff[this._const.name]] = diff[this._const.newValue];
-                    if (node.nodeName === 'INPUT' && diff[this._const.name] === 'value') {
-                        node.value = diff[this._const.value];
-                    }
-                    break;
-                case this._const.removeAttribute:
+.markdown-body a:not([href]) {
+  color: inherit;
+  text-decoration: none;
+}
 
-                    delete node.attributes[diff[this._const.name]];
+.markdown-body .anchor {
+  float: left;
+  padding-right: 4px;
+  margin-left: -20px;
+  line-height: 1;
+}
 
-                    if (Object.keys(node.attributes).length === 0) {
-                        delete node.attributes;
-                    }
+.markdown-body .anchor:focus {
+  outline: none;
+}
 
-                    if (diff[this._const.name] === 'checked') {
-                        node.checked = false;
-                    } else if (diff[this._const.name] === 'selected') {
-                        delete node.selected;
-                    } else if (node.nodeName === 'INPUT' && diff[this._const.name] === 'value') {
-                        delete node.value;
-                    }
+.markdown-body p,
+.markdown-body blockquote,
+.markdown-body ul,
+.markdown-body ol,
+.markdown-body dl,
+.markdown-body table,
+.markdown-body pre {
+  margin-top: 0;
+  margin-bottom: 16px;
+}
 
-                    break;
-                case this._const.modifyTextElement:
-                    node.data = diff[this._const.newValue];
-                    break;
-                case this._const.modifyValue:
-                    node.value = diff[this._const.newValue];
-                    break;
-                case this._const.modifyComment:
-                    node.data = diff[this._const.newValue];
-                    break;
-                case this._const.modifyChecked:
-                    node.checked = diff[this._const.newValue];
-                    break;
-                case this._const.modifySelected:
-                    node.selected = diff[this._const.newValue];
-                    break;
-                case this._const.replaceElement:
-                    newNode = cloneObj(diff[this._const.newValue]);
-                    newNode.outerDone = true;
-                    newNode.innerDone = true;
-                    newNode.valueDone = true;
-                    parentNode.childNodes[nodeIndex] = newNode;
-                    break;
-                case this._const.relocateGroup:
-                    nodeArray = node.childNodes.splice(diff[this._const.from], diff.groupLength).reverse();
-                    length = nodeArray.length;
-                    for (i = 0; i < length; i++) {
-                        movedNode = nodeArray[i];
-                        node.childNodes.splice(diff[t._const.to], 0, movedNode);
-                    }
-                    break;
-                case this._const.removeElement:
-                    parentNode.childNodes.splice(nodeIndex, 1);
-                    break;
-                case this._const.addElement:
-                    route = diff[this._const.route].slice();
-                    c = route.splice(route.length - 1, 1)[0];
-                    node = this.getFromVirtualRoute(tree, route).node;
-                    newNode = cloneObj(diff[this._const.element]);
-                    newNode.outerDone = true;
-                    newNode.innerDone = true;
-                    newNode.valueDone = true;
+.markdown-body hr {
+  height: 0.25em;
+  padding: 0;
+  margin: 24px 0;
+  background-color: #e1e4e8;
+  border: 0;
+}
 
-                    if (!node.childNodes) {
-                        node.childNodes = [];
-                    }
+.markdown-body blockquote {
+  padding: 0 1em;
+  color: #6a737d;
+  border-left: 0.25em solid #dfe2e5;
+}
 
-                    if (c >= node.childNodes.length) {
-                        node.childNodes.push(newNode);
-                    } else {
-                        node.childNodes.splice(c, 0, newNode);
-                    }
-                    break;
-                case this._const.removeTextElement:
-                    parentNode.childNodes.splice(nodeIndex, 1);
-                    if (parentNode.nodeName === 'TEXTAREA') {
-                        delete parentNode.value;
-                    }
-                    break;
-                case this._const.addTextElement:
-                    route = diff[this._const.route].slice();
-                    c = route.splice(route.length - 1, 1)[0];
-                    newNode = {};
-                    newNode.nodeName = '#text';
-                    newNode.data = diff[this._const.value];
-                    node = this.getFromVirtualRoute(tree, route).node;
-                    if (!node.childNodes) {
-                        node.childNodes = [];
-                    }
+.markdown-body blockquote>:first-child {
+  margin-top: 0;
+}
 
-                    if (c >= node.childNodes.length) {
-                        node.childNodes.push(newNode);
-                    } else {
-                        node.childNodes.splice(c, 0, newNode);
-                    }
-                    if (node.nodeName === 'TEXTAREA') {
-                        node.value = diff[this._const.newValue];
-                    }
-                    break;
-                default:
-                    console.log('unknown action');
-            }
+.markdown-body blockquote>:last-child {
+  margin-bottom: 0;
+}
 
-            // capture newNode for the callback
-            info.newNode = newNode;
-            this.postVirtualDiffApply(info);
+.markdown-body kbd {
+  display: inline-block;
+  padding: 3px 5px;
+  font-size: 11px;
+  line-height: 10px;
+  color: #444d56;
+  vertical-align: middle;
+  background-color: #fafbfc;
+  border: solid 1px #c6cbd1;
+  border-bottom-color: #959da5;
+  border-radius: 3px;
+  box-shadow: inset 0 -1px 0 #959da5;
+}
 
-            return;
-        },
+.markdown-body h1,
+.markdown-body h2,
+.markdown-body h3,
+.markdown-body h4,
+.markdown-body h5,
+.markdown-body h6 {
+  margin-top: 24px;
+  margin-bottom: 16px;
+  font-weight: 600;
+  line-height: 1.25;
+}
 
+.markdown-body h1 .octicon-link,
+.markdown-body h2 .octicon-link,
+.markdown-body h3 .octicon-link,
+.markdown-body h4 .octicon-link,
+.markdown-body h5 .octicon-link,
+.markdown-body h6 .octicon-link {
+  color: #1b1f23;
+  vertical-align: middle;
+  visibility: hidden;
+}
 
+.markdown-body h1:hover .anchor,
+.markdown-body h2:hover .anchor,
+.markdown-body h3:hover .anchor,
+.markdown-body h4:hover .anchor,
+.markdown-body h5:hover .anchor,
+.markdown-body h6:hover .anchor {
+  text-decoration: none;
+}
 
+.markdown-body h1:hover .anchor .octicon-link,
+.markdown-body h2:hover .anchor .octicon-link,
+.markdown-body h3:hover .anchor .octicon-link,
+.markdown-body h4:hover .anchor .octicon-link,
+.markdown-body h5:hover .anchor .octicon-link,
+.markdown-body h6:hover .anchor .octicon-link {
+  visibility: visible;
+}
 
-        // ===== Apply a diff =====
+.markdown-body h1 {
+  padding-bottom: 0.3em;
+  font-size: 2em;
+  border-bottom: 1px solid #eaecef;
+}
 
-        apply: function(tree, diffs) {
-            var dobj = this,
-                length = diffs.length,
-                diff, i;
+.markdown-body h2 {
+  padding-bottom: 0.3em;
+  font-size: 1.5em;
+  border-bottom: 1px solid #eaecef;
+}
 
-            if (length === 0) {
-                return true;
-            }
-            for (i = 0; i < length; i++) {
-                diff = diffs[i];
-                if (!dobj.applyDiff(tree, diff)) {
-                    return false;
-                }
-            }
-            return true;
-        },
-        getFromRoute: function(tree, route) {
-            route = route.slice();
-            var c, node = tree;
-            while (route.length > 0) {
-                if (!node.childNodes) {
-                    return false;
-                }
-                c = route.splice(0, 1)[0];
-                node = node.childNodes[c];
-            }
-            return node;
-        },
-        applyDiff: function(tree, diff) {
-            var node = this.getFromRoute(tree, diff[this._const.route]),
-                newNode, reference, route, nodeArray, length, childNode, index, c;
+.markdown-body h3 {
+  font-size: 1.25em;
+}
 
-            var t = this;
-            // pre-diff hook
-            var info = {
-                diff: diff,
-                node: node
-            };
+.markdown-body h4 {
+  font-size: 1em;
+}
 
-            if (this.preDiffApply(info)) {
-                return true;
-            }
+.markdown-body h5 {
+  font-size: 0.875em;
+}
 
-            switch (diff[this._const.action]) {
-                case this._const.addAttribute:
-                    if (!node || !node.setAttribute) {
-                        return false;
-                    }
-                    node.setAttribute(diff[this._const.name], diff[this._const.value]);
-                    break;
-                case this._const.modifyAttribute:
-                    if (!node || !node.setAttribute) {
-                        return false;
-                    }
-                    node.setAttribute(diff[this._const.name], diff[this._const.newValue]);
-                    break;
-                case this._const.removeAttribute:
-                    if (!node || !node.removeAttribute) {
-                        return false;
-                    }
-                    node.removeAttribute(diff[this._const.name]);
-                    break;
-                case this._const.modifyTextElement:
-                    if (!node || node.nodeType !== 3) {
-                        return false;
-                    }
-                    this.textDiff(node, node.data, diff[this._const.oldValue], diff[this._const.newValue]);
-                    break;
-                case this._const.modifyValue:
-                    if (!node || typeof node.value === 'undefined') {
-                        return false;
-                    }
-                    node.value = diff[this._const.newValue];
-                    break;
-                case this._const.modifyComment:
-                    if (!node || typeof node.data === 'undefined') {
-                        return false;
-                    }
-                    this.textDiff(node, node.data, diff[this._const.oldValue], diff[this._const.newValue]);
-                    break;
-                case this._const.modifyChecked:
-                    if (!node || typeof node.checked === 'undefined') {
-                        return false;
-                    }
-                    node.checked = diff[this._const.newValue];
-                    break;
-                case this._const.modifySelected:
-                    if (!node || typeof node.selected === 'undefined') {
-                        return false;
-                    }
-                    node.selected = diff[this._const.newValue];
-                    break;
-                case this._const.replaceElement:
-                    node.parentNode.replaceChild(this.objToNode(diff[this._const.newValue], node.namespaceURI === 'http://www.w3.org/2000/svg'), node);
-                    break;
-                case this._const.relocateGroup:
-                    nodeArray = Array.apply(null, new Array(diff.groupLength)).map(function() {
-                        return node.removeChild(node.childNodes[diff[t._const.from]]);
-                    });
-                    length = nodeArray.length;
-                    for (index = 0; index < length; index++) {
-                        childNode = nodeArray[index];
-                        if (index === 0) {
-                            reference = node.childNodes[diff[t._const.to]];
-                        }
-                        node.insertBefore(childNode, reference);
-                    }
-                    break;
-                case this._const.removeElement:
-                    this.scrollToMiddle(node);
-                    node.parentNode.removeChild(node);
-                    break;
-                case this._const.addElement:
-                    route = diff[this._const.route].slice();
-                    c = route.splice(route.length - 1, 1)[0];
-                    newNode = this.objToNode(diff[this._const.element], node.namespaceURI === 'http://www.w3.org/2000/svg');
-                    node = this.getFromRoute(tree, route);
-                    node.insertBefore(newNode, node.childNodes[c]);
-                    break;
-                case this._const.removeTextElement:
-                    if (!node || node.nodeType !== 3) {
-                        return false;
-                    }
-                    node.parentNode.removeChild(node);
-                    break;
-                case this._const.addTextElement:
-                    route = diff[this._const.route].slice();
-                    c = route.splice(route.length - 1, 1)[0];
-                    newNode = document.createTextNode(diff[this._const.value]);
-                    node = this.getFromRoute(tree, route);
-                    if (!node || !node.childNodes) {
-                        return false;
-                    }
-                    node.insertBefore(newNode, node.childNodes[c]);
-                    break;
-                default:
-                    console.log('unknown action');
-            }
+.markdown-body h6 {
+  font-size: 0.85em;
+  color: #6a737d;
+}
 
-            // if a new node was created, we might be interested in it
-            // post diff hook
-            info.newNode = newNode;
-            this.postDiffApply(info);
+.markdown-body ul,
+.markdown-body ol {
+  padding-left: 2em;
+}
 
-            // console.warn(diff[this._const.action]);
-            if (diff[this._const.action] != this._const.removeElement) {
-                if (newNode && newNode.scrollIntoView) {
-                    this.scrollToMiddle(newNode);
-                } else if (node && node.scrollIntoView) {
-                    this.scrollToMiddle(node);
-                } else if (node.parentNode && node.parentNode.scrollIntoView) {
-                    this.scrollToMiddle(node.parentNode);
-                }
-            }
+.markdown-body ul ul,
+.markdown-body ul ol,
+.markdown-body ol ol,
+.markdown-body ol ul {
+  margin-top: 0;
+  margin-bottom: 0;
+}
 
-            return true;
-        },
+.markdown-body li {
+  word-wrap: break-all;
+}
 
-        scrollToMiddle: function(element) {
-            var elementRect = element.getBoundingClientRect();
-            var absoluteElementTop = elementRect.top + window.pageYOffset;
-            var middle = absoluteElementTop - (window.innerHeight / 2);
-            window.scrollTo(0, middle);
-        },
+.markdown-body li>p {
+  margin-top: 16px;
+}
 
-        // ===== Undo a diff =====
+.markdown-body li+li {
+  margin-top: 0.25em;
+}
 
-        undo: function(tree, diffs) {
-            var dobj = this, diff, length = diffs.length, i;
-            diffs = diffs.slice();
-            if (!length) {
-                diffs = [diffs];
-            }
-            diffs.reverse();
-            for (i = 0; i < length; i++) {
-                diff = diffs[i];
-                dobj.undoDiff(tree, diff);
-            }
-        },
-        undoDiff: function(tree, diff) {
+.markdown-body dl {
+  padding: 0;
+}
 
-            switch (diff[this._const.action]) {
-                case this._const.addAttribute:
-                    diff[this._const.action] = this._const.removeAttribute;
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.modifyAttribute:
-                    swap(diff, this._const.oldValue, this._const.newValue);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.removeAttribute:
-                    diff[this._const.action] = this._const.addAttribute;
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.modifyTextElement:
-                    swap(diff, this._const.oldValue, this._const.newValue);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.modifyValue:
-                    swap(diff, this._const.oldValue, this._const.newValue);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.modifyComment:
-                    swap(diff, this._const.oldValue, this._const.newValue);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.modifyChecked:
-                    swap(diff, this._const.oldValue, this._const.newValue);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.modifySelected:
-                    swap(diff, this._const.oldValue, this._const.newValue);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.replaceElement:
-                    swap(diff, this._const.oldValue, this._const.newValue);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.relocateGroup:
-                    swap(diff, this._const.from, this._const.to);
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.removeElement:
-                    diff[this._const.action] = this._const.addElement;
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.addElement:
-                    diff[this._const.action] = this._const.removeElement;
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.removeTextElement:
-                    diff[this._const.action] = this._const.addTextElement;
-                    this.applyDiff(tree, diff);
-                    break;
-                case this._const.addTextElement:
-                    diff[this._const.action] = this._const.removeTextElement;
-                    this.applyDiff(tree, diff);
-                    break;
-                default:
-                    console.log('unknown action');
-            }
+.markdown-body dl dt {
+  padding: 0;
+  margin-top: 16px;
+  font-size: 1em;
+  font-style: italic;
+  font-weight: 600;
+}
 
-        }
-    };
+.markdown-body dl dd {
+  padding: 0 16px;
+  margin-bottom: 16px;
+}
 
-    return diffDOM;
-});
-`
-	html := fmt.Sprintf(`
-<!DOCTYPE html>
-<html lang="en">
-  <head>
-<script type="text/javascript" src="qrc:///qtwebchannel/qwebchannel.js"></script>
-<script>%s</script>
-    <style>
-     %s
-    </style>
-  </head>
-  <body>
-  %s
-  <script>
-  %s
-  </script>
-  </body>
-</html>
-`, morphdomjs, style, content, js)
+.markdown-body table {
+  display: block;
+  width: 100%;
+  overflow: auto;
+}
 
-	return html
+.markdown-body table th {
+  font-weight: 600;
+}
+
+.markdown-body table th,
+.markdown-body table td {
+  padding: 6px 13px;
+  border: 1px solid #dfe2e5;
+}
+
+.markdown-body table tr {
+  background-color: #fff;
+  border-top: 1px solid #c6cbd1;
 }
+
+.markdown-body table tr:nth-child(2n) {
+  background-color: #f6f8fa;
+}
+
+.markdown-body img {
+  max-width: 100%;
+  box-sizing: content-box;
+  background-color: #fff;
+}
+
+.markdown-body img[align=right] {
+  padding-left: 20px;
+}
+
+.markdown-body img[align=left] {
+  padding-right: 20px;
+}
+
+.markdown-body code {
+  padding: 0.2em 0.4em;
+  margin: 0;
+  font-size: 85%;
+  background-color: rgba(27,31,35,0.05);
+  border-radius: 3px;
+}
+
+.markdown-body pre {
+  word-wrap: normal;
+}
+
+.markdown-body pre>code {
+  padding: 0;
+  margin: 0;
+  font-size: 100%;
+  word-break: normal;
+  white-space: pre;
+  background: transparent;
+  border: 0;
+}
+
+.markdown-body .highlight {
+  margin-bottom: 16px;
+}
+
+.markdown-body .highlight pre {
+  margin-bottom: 0;
+  word-break: normal;
+}
+
+.markdown-body .highlight pre,
+.markdown-body pre {
+  padding: 16px;
+  overflow: auto;
+  font-size: 85%;
+  line-height: 1.45;
+  background-color: #f6f8fa;
+  border-radius: 3px;
+}
+
+.markdown-body pre code {
+  display: inline;
+  max-width: auto;
+  padding: 0;
+  margin: 0;
+  overflow: visible;
+  line-height: inherit;
+  word-wrap: normal;
+  background-color: transparent;
+  border: 0;
+}
+
+.markdown-body .full-commit .btn-outline:not(:disabled):hover {
+  color: #005cc5;
+  border-color: #005cc5;
+}
+
+.markdown-body kbd {
+  display: inline-block;
+  padding: 3px 5px;
+  font: 11px "SFMono-Regular", Consolas, "Liberation Mono", Menlo, Courier, monospace;
+  line-height: 10px;
+  color: #444d56;
+  vertical-align: middle;
+  background-color: #fafbfc;
+  border: solid 1px #d1d5da;
+  border-bottom-color: #c6cbd1;
+  border-radius: 3px;
+  box-shadow: inset 0 -1px 0 #c6cbd1;
+}
+
+.markdown-body :checked+.radio-label {
+  position: relative;
+  z-index: 1;
+  border-color: #0366d6;
+}
+
+.markdown-body .task-list-item {
+  list-style-type: none;
+}
+
+.markdown-body .task-list-item+.task-list-item {
+  margin-top: 3px;
+}
+
+.markdown-body .task-list-item input {
+  margin: 0 0.2em 0.25em -1.6em;
+  vertical-align: middle;
+}
+
+.markdown-body hr {
+  border-bottom-color: #eee;
+}
+`
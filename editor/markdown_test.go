@@ -0,0 +1,160 @@
+package editor
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestEmbedLocalImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goneovim-markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "a b.png"), png, 0644); err != nil {
+		t.Fatal(err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			"embedLocalImages() relative path is embedded",
+			`<p><img src="a%20b.png" alt="a"></p>`,
+			`<p><img src="data:image/png;base64,` + encoded + `" alt="a"></p>`,
+		},
+		{
+			"embedLocalImages() absolute file url is embedded",
+			`<img alt="a" src="file://` + filepath.Join(dir, "a b.png") + `">`,
+			`<img alt="a" src="data:image/png;base64,` + encoded + `">`,
+		},
+		{
+			"embedLocalImages() remote image is kept",
+			`<img src="https://example.com/a.png" alt="a">`,
+			`<img src="https://example.com/a.png" alt="a">`,
+		},
+		{
+			"embedLocalImages() missing image is kept",
+			`<img src="missing.png" alt="a">`,
+			`<img src="missing.png" alt="a">`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := embedLocalImages(tt.body, dir); got != tt.want {
+				t.Errorf("embedLocalImages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlankFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"blankFrontMatter() yaml front matter is blanked",
+			"---\ntitle: a\n---\n# Head\n",
+			"\n\n\n# Head\n",
+		},
+		{
+			"blankFrontMatter() toml front matter is blanked",
+			"+++\ntitle = \"a\"\n+++\ntext\n",
+			"\n\n\ntext\n",
+		},
+		{
+			"blankFrontMatter() unterminated front matter is kept",
+			"---\ntitle: a\n",
+			"---\ntitle: a\n",
+		},
+		{
+			"blankFrontMatter() thematic break in body is kept",
+			"# Head\n---\n",
+			"# Head\n---\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(blankFrontMatter([]byte(tt.content))); got != tt.want {
+				t.Errorf("blankFrontMatter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveLocalReferences(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goneovim-markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	image := filepath.Join(dir, "a b.png")
+	if err := ioutil.WriteFile(image, []byte("\x89PNG\r\n\x1a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageURL := localFileURL(image) + "?t=" + strconv.FormatInt(info.ModTime().UnixNano(), 10)
+	docURL := localFileURL(filepath.Join(dir, "doc", "b.md"))
+
+	tests := []struct {
+		name       string
+		body       string
+		want       string
+		wantImages []string
+	}{
+		{
+			"resolveLocalReferences() relative image is resolved and watched",
+			`<img src="a%20b.png" alt="a">`,
+			`<img src="` + imageURL + `" alt="a">`,
+			[]string{image},
+		},
+		{
+			"resolveLocalReferences() missing image is resolved but not watched",
+			`<img src="missing.png" alt="a">`,
+			`<img src="` + localFileURL(filepath.Join(dir, "missing.png")) + `" alt="a">`,
+			nil,
+		},
+		{
+			"resolveLocalReferences() relative link keeps its fragment",
+			`<a href="doc/b.md#usage">b</a>`,
+			`<a href="` + docURL + `#usage">b</a>`,
+			nil,
+		},
+		{
+			"resolveLocalReferences() anchors and remote links are kept",
+			`<a href="#usage">u</a><a href="https://example.com/b.md">b</a><img src="https://example.com/a.png">`,
+			`<a href="#usage">u</a><a href="https://example.com/b.md">b</a><img src="https://example.com/a.png">`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotImages := resolveLocalReferences(tt.body, dir)
+			if got != tt.want {
+				t.Errorf("resolveLocalReferences() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotImages, tt.wantImages) {
+				t.Errorf("resolveLocalReferences() images = %v, want %v", gotImages, tt.wantImages)
+			}
+		})
+	}
+}
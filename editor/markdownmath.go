@@ -0,0 +1,221 @@
+package editor
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	gmutil "github.com/yuin/goldmark/util"
+)
+
+// The math extension parses TeX math delimited by "$" and "$$", and renders
+// it with the "\(...\)" and "\[...\]" delimiters KaTeX's auto-render finds.
+// The TeX source is kept as text, so that it stays readable without KaTeX.
+
+// kindMathInline is the kind of inline math nodes
+var kindMathInline = ast.NewNodeKind("MathInline")
+
+// kindMathBlock is the kind of display math blocks
+var kindMathBlock = ast.NewNodeKind("MathBlock")
+
+// mathInline is "$...$", or "$$...$$" in a paragraph
+type mathInline struct {
+	ast.BaseInline
+	value   []byte
+	display bool
+}
+
+func (n *mathInline) Kind() ast.NodeKind {
+	return kindMathInline
+}
+
+func (n *mathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": string(n.value)}, nil)
+}
+
+// mathBlock is the lines between "$$" lines
+type mathBlock struct {
+	ast.BaseBlock
+}
+
+func (n *mathBlock) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+func (n *mathBlock) IsRaw() bool {
+	return true
+}
+
+func (n *mathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type mathInlineParser struct{}
+
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	value, length, display := parseInlineMath(line)
+	if length == 0 {
+		return nil
+	}
+	block.Advance(length)
+
+	return &mathInline{value: value, display: display}
+}
+
+// parseInlineMath parses the math at the start of the line, and returns the
+// TeX source, the length of the math with its delimiters, and whether it is
+// display math. The length is 0 if the line does not start with math. As
+// pandoc does, "$" must not be followed by a space to open inline math, and
+// the closing "$" must neither follow a space nor precede a digit, so that
+// prices like "$5 and $6" are not math.
+func parseInlineMath(line []byte) ([]byte, int, bool) {
+	if bytes.HasPrefix(line, []byte("$$")) {
+		end := bytes.Index(line[2:], []byte("$$"))
+		if end <= 0 {
+			return nil, 0, false
+		}
+		return line[2 : 2+end], end + 4, true
+	}
+	if len(line) < 3 || line[0] != '$' || isMathSpace(line[1]) {
+		return nil, 0, false
+	}
+	for i := 2; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '$':
+			if isMathSpace(line[i-1]) || (i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9') {
+				return nil, 0, false
+			}
+			return line[1:i], i + 1, false
+		}
+	}
+
+	return nil, 0, false
+}
+
+func isMathSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+type mathBlockParser struct{}
+
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	if !isMathFence(line) {
+		return nil, parser.NoChildren
+	}
+	reader.Advance(lineLength(line))
+
+	return &mathBlock{}, parser.NoChildren
+}
+
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	if isMathFence(line) {
+		reader.Advance(lineLength(line))
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(lineLength(line))
+
+	return parser.Continue | parser.NoChildren
+}
+
+func (p *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// lineLength returns the length of the line without the newline, which the
+// parser advances over by itself
+func lineLength(line []byte) int {
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		return len(line) - 1
+	}
+
+	return len(line)
+}
+
+// isMathFence reports whether the line is "$$" alone
+func isMathFence(line []byte) bool {
+	return string(bytes.TrimSpace(line)) == "$$"
+}
+
+type mathRenderer struct{}
+
+func (r *mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMathInline, r.renderInline)
+	reg.Register(kindMathBlock, r.renderBlock)
+}
+
+func (r *mathRenderer) renderInline(w gmutil.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*mathInline)
+	if n.display {
+		w.WriteString(`<span class="math display">\[`)
+		w.Write(gmutil.EscapeHTML(n.value))
+		w.WriteString(`\]</span>`)
+	} else {
+		w.WriteString(`<span class="math inline">\(`)
+		w.Write(gmutil.EscapeHTML(n.value))
+		w.WriteString(`\)</span>`)
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *mathRenderer) renderBlock(w gmutil.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	w.WriteString(`<p class="math display"`)
+	html.RenderAttributes(w, node, nil)
+	w.WriteString(`>\[`)
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		w.Write(gmutil.EscapeHTML(segment.Value(source)))
+	}
+	w.WriteString("\\]</p>\n")
+
+	return ast.WalkSkipChildren, nil
+}
+
+type mathExtension struct{}
+
+// mathMarkdown is the goldmark extension for math
+var mathMarkdown = &mathExtension{}
+
+func (e *mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(gmutil.Prioritized(&mathBlockParser{}, 700)),
+		parser.WithInlineParsers(gmutil.Prioritized(&mathInlineParser{}, 500)),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(gmutil.Prioritized(&mathRenderer{}, 500)),
+	)
+}
@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yuin/goldmark"
+)
+
+func TestParseInlineMath(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		value   string
+		length  int
+		display bool
+	}{
+		{"inline", "$x^2$ is", "x^2", 5, false},
+		{"display", "$$a+b$$ c", "a+b", 7, true},
+		{"escaped dollar", `$a\$b$`, `a\$b`, 6, false},
+		{"space after open", "$ x$", "", 0, false},
+		{"space before close", "$x $", "", 0, false},
+		{"price", "$5 and $6", "", 0, false},
+		{"unclosed", "$x", "", 0, false},
+		{"empty display", "$$$$", "", 0, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			value, length, display := parseInlineMath([]byte(tt.line))
+			if string(value) != tt.value || length != tt.length || display != tt.display {
+				t.Errorf("parseInlineMath(%q) = %q, %d, %v, want %q, %d, %v", tt.line, value, length, display, tt.value, tt.length, tt.display)
+			}
+		})
+	}
+}
+
+func TestMathMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			"inline math",
+			"Euler $e^{i\\pi} < 0$ costs $5.\n",
+			"<p>Euler <span class=\"math inline\">\\(e^{i\\pi} &lt; 0\\)</span> costs $5.</p>\n",
+		},
+		{
+			"math block",
+			"$$\na_1 * b_2\n$$\n",
+			"<p class=\"math display\">\\[a_1 * b_2\n\\]</p>\n",
+		},
+	}
+	md := goldmark.New(goldmark.WithExtensions(mathMarkdown))
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := md.Convert([]byte(tt.markdown), &buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Convert(%q) = %q, want %q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
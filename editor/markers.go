@@ -0,0 +1,230 @@
+package editor
+
+import (
+	"github.com/neovim/go-client/nvim"
+)
+
+// bufferMarker is a position in the current buffer to be marked on the
+// minimap and the scrollbar, such as a diagnostic or a search match.
+type bufferMarker struct {
+	Line int    `msgpack:"line"`
+	Col  int    `msgpack:"col"`
+	Kind string `msgpack:"kind"`
+}
+
+// bufferMarkerKinds selects the kinds of markers to fetch
+type bufferMarkerKinds struct {
+	Diagnostics bool `msgpack:"diagnostics"`
+	Search      bool `msgpack:"search"`
+	Git         bool `msgpack:"git"`
+	// RefreshGit reloads the contents of HEAD that git changes are compared with
+	RefreshGit bool `msgpack:"refresh_git"`
+	// SearchBudget bounds the milliseconds spent on finding search matches,
+	// which are left out past it. 0 means no limit.
+	SearchBudget int `msgpack:"search_budget"`
+}
+
+// bufferMarkers is the result of bufferMarkersLua
+type bufferMarkers struct {
+	Markers []bufferMarker `msgpack:"markers"`
+	Colors  map[string]int `msgpack:"colors"`
+}
+
+// bufferMarkersLua returns the markers of the current buffer between the given
+// 1-based lines, along with the colors of the highlight groups for each kind.
+// The contents of the file at git HEAD are cached in a buffer variable, and the
+// buffer is compared with them, so that unsaved changes are marked as well.
+// Both are done in the background, and the hunks are cached until the buffer
+// or HEAD changes. Search matches are found in chunks of lines until the
+// search budget runs out.
+const bufferMarkersLua = `
+local top, bottom, kinds = ...
+local buf = vim.api.nvim_get_current_buf()
+local markers = {}
+local function add(line, col, kind)
+  if line >= top and line <= bottom then
+    table.insert(markers, {line = line, col = col, kind = kind})
+  end
+end
+
+if kinds.diagnostics and vim.diagnostic then
+  local severities = {'error', 'warn', 'info', 'hint'}
+  for _, d in ipairs(vim.diagnostic.get(buf)) do
+    add(d.lnum + 1, d.col, severities[d.severity] or 'info')
+  end
+end
+
+if kinds.search and vim.v.hlsearch == 1 then
+  local pattern = vim.fn.getreg('/')
+  if pattern ~= '' then
+    pcall(function()
+      local last = math.min(bottom, vim.api.nvim_buf_line_count(buf))
+      local deadline = kinds.search_budget > 0 and vim.loop.hrtime() + kinds.search_budget * 1e6
+      local chunk = 1000
+      for first = top, last, chunk do
+        if deadline and vim.loop.hrtime() > deadline then
+          break
+        end
+        local lines = vim.api.nvim_buf_get_lines(buf, first - 1, math.min(first + chunk - 1, last), false)
+        for i, line in ipairs(lines) do
+          local col = vim.fn.match(line, pattern)
+          while col >= 0 do
+            add(first + i - 1, col, 'search')
+            local e = vim.fn.matchstrpos(line, pattern, col)[3]
+            col = vim.fn.match(line, pattern, math.max(e, col + 1))
+          end
+        end
+      end
+    end)
+  end
+end
+
+if kinds.git and vim.diff and vim.bo[buf].buftype == '' then
+  -- HEAD is read by a job, and the buffer is compared with it after this call
+  -- returns, so that neither holds up the redraw. The GUI fetches the markers
+  -- again once they are done.
+  local function done()
+    vim.rpcnotify(0, 'Gui', 'gonvim_scrollbar_marks', false)
+    vim.rpcnotify(0, 'Gui', 'gonvim_minimap_sync')
+  end
+  local path = vim.api.nvim_buf_get_name(buf)
+  if (kinds.refresh_git or vim.b[buf].gonvim_git_head == nil) and not vim.b[buf].gonvim_git_reading then
+    local job = 0
+    if path ~= '' then
+      local out = {}
+      job = vim.fn.jobstart({'git', '-C', vim.fn.fnamemodify(path, ':h'), 'show', 'HEAD:./' .. vim.fn.fnamemodify(path, ':t')}, {
+        stdout_buffered = true,
+        on_stdout = function(_, data)
+          out = data
+        end,
+        on_exit = function(_, code)
+          if not vim.api.nvim_buf_is_valid(buf) then
+            return
+          end
+          vim.b[buf].gonvim_git_reading = nil
+          vim.b[buf].gonvim_git_head = code == 0 and table.concat(out, '\n') or false
+          vim.b[buf].gonvim_git_hunks = nil
+          done()
+        end,
+      })
+    end
+    if job > 0 then
+      vim.b[buf].gonvim_git_reading = true
+    else
+      vim.b[buf].gonvim_git_head = false
+    end
+  end
+  local head = vim.b[buf].gonvim_git_head
+  local cache = vim.b[buf].gonvim_git_hunks
+  local tick = vim.api.nvim_buf_get_changedtick(buf)
+  if head and (cache == nil or cache.tick ~= tick) and not vim.b[buf].gonvim_git_diffing then
+    vim.b[buf].gonvim_git_diffing = true
+    vim.schedule(function()
+      if not vim.api.nvim_buf_is_valid(buf) then
+        return
+      end
+      vim.b[buf].gonvim_git_diffing = nil
+      local head = vim.b[buf].gonvim_git_head
+      if not head then
+        return
+      end
+      local current = table.concat(vim.api.nvim_buf_get_lines(buf, 0, -1, false), '\n') .. '\n'
+      local ok, hunks = pcall(vim.diff, head, current, {result_type = 'indices'})
+      vim.b[buf].gonvim_git_hunks = {tick = vim.api.nvim_buf_get_changedtick(buf), hunks = ok and hunks or {}}
+      done()
+    end)
+  end
+  -- The previous hunks are marked until the buffer is compared again
+  if head and cache then
+    for _, h in ipairs(cache.hunks) do
+      local count_a, start_b, count_b = h[2], h[3], h[4]
+      if count_b == 0 then
+        add(math.max(start_b, 1), 0, 'delete')
+      else
+        local kind = count_a == 0 and 'add' or 'change'
+        for line = math.max(start_b, top), math.min(start_b + count_b - 1, bottom) do
+          add(line, 0, kind)
+        end
+      end
+    end
+  end
+end
+
+local colors = vim.empty_dict()
+local groups = {
+  error = {'DiagnosticError', 'foreground'},
+  warn = {'DiagnosticWarn', 'foreground'},
+  info = {'DiagnosticInfo', 'foreground'},
+  hint = {'DiagnosticHint', 'foreground'},
+  search = {'Search', 'background'},
+  add = {'DiffAdd', 'background'},
+  change = {'DiffChange', 'background'},
+  delete = {'DiffDelete', 'background'},
+  cursor = {'CursorLineNr', 'foreground'},
+}
+for kind, group in pairs(groups) do
+  local ok, hl = pcall(vim.api.nvim_get_hl_by_name, group[1], true)
+  if ok and hl[group[2]] then
+    colors[kind] = hl[group[2]]
+  end
+end
+
+return {markers = markers, colors = colors}
+`
+
+// fetchBufferMarkers returns the markers of the current buffer between the 1-based lines top and bottom
+func fetchBufferMarkers(neovim *nvim.Nvim, top, bottom int, kinds bufferMarkerKinds) (*bufferMarkers, error) {
+	var markers *bufferMarkers
+	err := neovim.ExecLua(bufferMarkersLua, &markers, top, bottom, kinds)
+	if err != nil {
+		return nil, err
+	}
+	if markers == nil {
+		markers = &bufferMarkers{}
+	}
+
+	return markers, nil
+}
+
+// markerColor returns the color of the kind of marker
+func (m *bufferMarkers) markerColor(kind string) *RGBA {
+	if color, ok := m.Colors[kind]; ok {
+		return calcColor(color)
+	}
+	switch kind {
+	case "error", "delete":
+		return newRGBA(229, 57, 53, 1)
+	case "warn":
+		return newRGBA(255, 179, 0, 1)
+	case "info", "change":
+		return newRGBA(30, 136, 229, 1)
+	case "hint":
+		return newRGBA(0, 172, 193, 1)
+	case "add":
+		return newRGBA(67, 160, 71, 1)
+	case "search":
+		return newRGBA(255, 235, 59, 1)
+	default:
+		return editor.colors.fg
+	}
+}
+
+// markerPriority returns which kind of marker is drawn when several share a line
+func markerPriority(kind string) int {
+	switch kind {
+	case "error":
+		return 8
+	case "warn":
+		return 7
+	case "info":
+		return 6
+	case "hint":
+		return 5
+	case "search":
+		return 4
+	case "cursor":
+		return 9
+	default:
+		return 0
+	}
+}
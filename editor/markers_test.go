@@ -0,0 +1,33 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFetchBufferMarkersSearch(t *testing.T) {
+	neovim := startTestNvim(t)
+	setTestBuffer(t, neovim, []string{
+		"foo bar foo foofoo",
+		"bar",
+		"foo",
+	})
+	if err := neovim.Command(`let @/ = "foo" | set hlsearch | let v:hlsearch = 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	markers, err := fetchBufferMarkers(neovim, 1, 3, bufferMarkerKinds{Search: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bufferMarker{
+		{Line: 1, Col: 0, Kind: "search"},
+		{Line: 1, Col: 8, Kind: "search"},
+		{Line: 1, Col: 12, Kind: "search"},
+		{Line: 1, Col: 15, Kind: "search"},
+		{Line: 3, Col: 0, Kind: "search"},
+	}
+	if !reflect.DeepEqual(markers.Markers, want) {
+		t.Errorf("fetchBufferMarkers() markers = %v, want %v", markers.Markers, want)
+	}
+}
@@ -0,0 +1,337 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// menuFeedKeysLua types the rhs of a menu item, as neovim does for :emenu
+const menuFeedKeysLua = `
+local rhs, noremap = ...
+vim.api.nvim_feedkeys(vim.api.nvim_replace_termcodes(rhs, true, true, true), noremap and "n" or "m", false)
+`
+
+// menuPopupLua moves the cursor with the click if any, runs the MenuPopup
+// autocmds and sends the PopUp menu, so that the menu reflects the clicked
+// position
+const menuPopupLua = `
+local click, mode = ...
+if click ~= "" then
+  vim.api.nvim_feedkeys(vim.api.nvim_replace_termcodes(click, true, true, true), "x", false)
+end
+if vim.fn.exists("#MenuPopup") == 1 then
+  vim.cmd("doautocmd <nomodeline> MenuPopup " .. mode)
+end
+vim.rpcnotify(0, "Gui", "gonvim_context_menu", vim.fn.menu_get("PopUp", "a"))
+`
+
+// nvimMenu is an item of the menus returned by menu_get(). The items with a
+// submenu are menus, and the others are leaves run with the mappings.
+type nvimMenu struct {
+	name     string
+	shortcut string
+	actext   string
+	tooltip  string
+	hidden   bool
+	mappings map[string]*nvimMenuMapping
+	submenu  []*nvimMenu
+}
+
+// nvimMenuMapping is the command of a menu item in a mode
+type nvimMenuMapping struct {
+	rhs     string
+	noremap bool
+	enabled bool
+}
+
+// menuAction is a Qt action made for a leaf of the menus
+type menuAction struct {
+	action *widgets.QAction
+	item   *nvimMenu
+}
+
+// Menu shows the menus of neovim as the native menu bar and context menu
+type Menu struct {
+	ws        *Workspace
+	bar       *widgets.QMenuBar
+	barMenus  []*widgets.QMenu
+	actions   []*menuAction
+	popup     *nvimMenu
+	popupPos  *core.QPoint
+	isBarShow bool
+	// mouseModel is 'mousemodel', as right clicks pop up the menu only if it
+	// is "popup" or "popup_setpos"
+	mouseModel string
+}
+
+func newMenu() *Menu {
+	bar := widgets.NewQMenuBar(nil)
+	bar.SetNativeMenuBar(false)
+	bar.Hide()
+
+	return &Menu{
+		bar: bar,
+	}
+}
+
+// parseMenus converts the result of menu_get()
+func parseMenus(arg interface{}) []*nvimMenu {
+	items, ok := arg.([]interface{})
+	if !ok {
+		return nil
+	}
+	menus := []*nvimMenu{}
+	for _, i := range items {
+		d, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		menu := &nvimMenu{}
+		menu.name, _ = d["name"].(string)
+		menu.shortcut, _ = d["shortcut"].(string)
+		menu.actext, _ = d["actext"].(string)
+		menu.tooltip, _ = d["tooltip"].(string)
+		menu.hidden = util.ReflectToInt(d["hidden"]) != 0
+		if submenu, ok := d["submenu"]; ok {
+			menu.submenu = parseMenus(submenu)
+		}
+		if mappings, ok := d["mappings"].(map[string]interface{}); ok {
+			menu.mappings = make(map[string]*nvimMenuMapping)
+			for mode, m := range mappings {
+				mapping, ok := m.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				rhs, _ := mapping["rhs"].(string)
+				menu.mappings[mode] = &nvimMenuMapping{
+					rhs:     rhs,
+					noremap: util.ReflectToInt(mapping["noremap"]) != 0,
+					enabled: util.ReflectToInt(mapping["enabled"]) != 0,
+				}
+			}
+		}
+		menus = append(menus, menu)
+	}
+
+	return menus
+}
+
+// isMenuSeparator reports whether the menu name is a separator, like "-SEP1-"
+func isMenuSeparator(name string) bool {
+	return len(name) >= 2 && strings.HasPrefix(name, "-") && strings.HasSuffix(name, "-")
+}
+
+// menuLabel returns the text of the Qt action, where the shortcut of the menu
+// is marked with "&" again
+func menuLabel(item *nvimMenu) string {
+	label := strings.ReplaceAll(item.name, "&", "&&")
+	if item.shortcut != "" && item.shortcut != "&" {
+		if i := strings.Index(label, item.shortcut); i >= 0 {
+			label = label[:i] + "&" + label[i:]
+		}
+	}
+	if item.actext != "" {
+		label += "\t" + item.actext
+	}
+
+	return label
+}
+
+// menuModeOf returns the mode of the mappings of menus in the mode of
+// mode_change, or "" if menus can not be used in the mode
+func menuModeOf(mode string) string {
+	switch mode {
+	case "normal":
+		return "n"
+	case "visual":
+		return "v"
+	case "visual_select":
+		return "s"
+	case "operator":
+		return "o"
+	case "insert", "replace":
+		return "i"
+	case "cmdline_normal", "cmdline_insert", "cmdline_replace":
+		return "c"
+	case "terminal", "terminal-input":
+		return "tl"
+	}
+
+	return ""
+}
+
+// update rebuilds the menus from the result of menu_get("", "a")
+func (m *Menu) update(arg interface{}) {
+	m.popup = nil
+	items := []*nvimMenu{}
+	for _, item := range parseMenus(arg) {
+		switch {
+		case item.name == "PopUp":
+			m.popup = item
+		case item.name == "ToolBar", item.hidden, item.submenu == nil:
+		default:
+			items = append(items, item)
+		}
+	}
+	if !editor.config.Menu.Visible {
+		return
+	}
+
+	for _, menu := range m.barMenus {
+		menu.DeleteLater()
+	}
+	m.bar.Clear()
+	m.barMenus = nil
+	m.actions = nil
+	for _, item := range items {
+		menu := m.bar.AddMenu2(menuLabel(item))
+		menu.SetToolTipsVisible(true)
+		menu.ConnectAboutToShow(func() {
+			m.updateActions(m.actions, false)
+		})
+		m.actions = append(m.actions, m.addItems(menu, item.submenu)...)
+		m.barMenus = append(m.barMenus, menu)
+	}
+	m.setColor()
+	m.updateBar()
+}
+
+// addItems adds the items to the Qt menu, and returns the actions of the leaves
+func (m *Menu) addItems(menu *widgets.QMenu, items []*nvimMenu) []*menuAction {
+	actions := []*menuAction{}
+	for _, item := range items {
+		if isMenuSeparator(item.name) {
+			menu.AddSeparator()
+			continue
+		}
+		if item.submenu != nil {
+			submenu := menu.AddMenu2(menuLabel(item))
+			submenu.SetToolTipsVisible(true)
+			actions = append(actions, m.addItems(submenu, item.submenu)...)
+			continue
+		}
+		item := item
+		action := menu.AddAction(menuLabel(item))
+		action.SetToolTip(item.tooltip)
+		action.ConnectTriggered(func(checked bool) {
+			m.execute(item)
+		})
+		actions = append(actions, &menuAction{action: action, item: item})
+	}
+
+	return actions
+}
+
+// updateActions enables the actions which have a mapping in the current mode.
+// The actions without one are hidden if hideUnmapped is set, as the context
+// menu only lists the items of the mode.
+func (m *Menu) updateActions(actions []*menuAction, hideUnmapped bool) {
+	mode := menuModeOf(m.ws.mode)
+	for _, a := range actions {
+		mapping := a.item.mappings[mode]
+		a.action.SetVisible(mapping != nil || !hideUnmapped)
+		a.action.SetEnabled(mapping != nil && mapping.enabled)
+	}
+}
+
+// execute runs the menu item in the current mode
+func (m *Menu) execute(item *nvimMenu) {
+	mapping := item.mappings[menuModeOf(m.ws.mode)]
+	if mapping == nil || !mapping.enabled {
+		return
+	}
+	go m.ws.nvim.ExecLua(menuFeedKeysLua, nil, mapping.rhs, mapping.noremap)
+}
+
+// updateBar shows the menu bar if it has menus, unless it is hidden until Alt
+// is pressed
+func (m *Menu) updateBar() {
+	if !editor.config.Menu.Visible {
+		return
+	}
+	if len(m.barMenus) == 0 || (editor.config.Menu.AutoHide && !m.isBarShow) {
+		m.bar.Hide()
+		return
+	}
+	m.bar.Show()
+}
+
+// toggleBar shows or hides the menu bar hidden by Menu.AutoHide
+func (m *Menu) toggleBar() {
+	if !editor.config.Menu.AutoHide {
+		return
+	}
+	m.isBarShow = !m.isBarShow
+	m.updateBar()
+}
+
+// hasContextMenu reports whether right clicks show the PopUp menu of neovim
+// instead of being sent to neovim
+func (m *Menu) hasContextMenu() bool {
+	return editor.config.Menu.ContextMenu &&
+		strings.HasPrefix(m.mouseModel, "popup") &&
+		m.popup != nil &&
+		menuModeOf(m.ws.mode) != ""
+}
+
+// setMouseModel keeps the value of 'mousemodel'
+func (m *Menu) setMouseModel(arg interface{}) {
+	m.mouseModel, _ = arg.(string)
+}
+
+// requestContextMenu asks neovim for the PopUp menu after the MenuPopup
+// autocmds are run. As 'mousemodel' "popup_setpos", the cursor is moved to
+// the clicked position first unless text is selected.
+func (m *Menu) requestContextMenu(event *gui.QMouseEvent) {
+	m.popupPos = event.GlobalPos()
+	mode := menuModeOf(m.ws.mode)
+	click := ""
+	if m.mouseModel == "popup_setpos" && mode != "v" && mode != "s" {
+		x, y := m.ws.screen.mouseCell(event)
+		click = fmt.Sprintf("<LeftMouse><%d,%d><LeftRelease><%d,%d>", x, y, x, y)
+	}
+	go m.ws.nvim.ExecLua(menuPopupLua, nil, click, mode)
+}
+
+// showContextMenu shows the PopUp menu of the result of menu_get("PopUp", "a")
+func (m *Menu) showContextMenu(arg interface{}) {
+	items := parseMenus(arg)
+	if len(items) == 0 || len(items[0].submenu) == 0 || m.popupPos == nil {
+		return
+	}
+	menu := widgets.NewQMenu(m.ws.screen.widget)
+	menu.SetToolTipsVisible(true)
+	if editor.colors.widgetFg != nil && editor.colors.widgetBg != nil {
+		menu.SetStyleSheet(m.styleSheet())
+	}
+	actions := m.addItems(menu, items[0].submenu)
+	m.updateActions(actions, true)
+	menu.Exec2(m.popupPos, nil)
+	menu.DeleteLater()
+}
+
+func (m *Menu) styleSheet() string {
+	return fmt.Sprintf(`
+		QMenuBar, QMenu { color: %[1]s; background-color: %[2]s; }
+		QMenuBar::item { background-color: transparent; padding: 2px 8px; }
+		QMenuBar::item:selected, QMenu::item:selected { background-color: %[3]s; }
+		QMenu::item:disabled { color: %[4]s; }
+		`,
+		editor.colors.widgetFg.String(),
+		editor.colors.widgetBg.String(),
+		editor.colors.selectedBg.String(),
+		editor.colors.inactiveFg.String(),
+	)
+}
+
+func (m *Menu) setColor() {
+	if editor.colors.widgetFg == nil || editor.colors.widgetBg == nil {
+		return
+	}
+	m.bar.SetStyleSheet(m.styleSheet())
+}
@@ -0,0 +1,133 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMenus(t *testing.T) {
+	arg := []interface{}{
+		map[string]interface{}{
+			"name":     "File",
+			"shortcut": "F",
+			"priority": int64(10),
+			"hidden":   int64(0),
+			"submenu": []interface{}{
+				map[string]interface{}{
+					"name":     "Save",
+					"priority": int64(500),
+					"hidden":   int64(0),
+					"actext":   ":w",
+					"tooltip":  "Save the file",
+					"mappings": map[string]interface{}{
+						"n": map[string]interface{}{"rhs": ":w<CR>", "silent": int64(0), "enabled": int64(1), "noremap": int64(1), "sid": int64(0)},
+						"i": map[string]interface{}{"rhs": "<C-O>:w<CR>", "silent": int64(0), "enabled": int64(0), "noremap": int64(0), "sid": int64(0)},
+					},
+				},
+			},
+		},
+		map[string]interface{}{
+			"name":    "]Hidden",
+			"hidden":  uint64(1),
+			"submenu": []interface{}{},
+		},
+		"invalid",
+	}
+	want := []*nvimMenu{
+		{
+			name:     "File",
+			shortcut: "F",
+			submenu: []*nvimMenu{
+				{
+					name:    "Save",
+					actext:  ":w",
+					tooltip: "Save the file",
+					mappings: map[string]*nvimMenuMapping{
+						"n": {rhs: ":w<CR>", noremap: true, enabled: true},
+						"i": {rhs: "<C-O>:w<CR>"},
+					},
+				},
+			},
+		},
+		{
+			name:    "]Hidden",
+			hidden:  true,
+			submenu: []*nvimMenu{},
+		},
+	}
+	if got := parseMenus(arg); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMenus() = %+v, want %+v", got, want)
+	}
+	if got := parseMenus(nil); got != nil {
+		t.Errorf("parseMenus(nil) = %+v, want nil", got)
+	}
+}
+
+func TestIsMenuSeparator(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"-SEP1-", true},
+		{"--", true},
+		{"-", false},
+		{"Save", false},
+		{"-Save", false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMenuSeparator(tt.name); got != tt.want {
+				t.Errorf("isMenuSeparator(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMenuLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		item *nvimMenu
+		want string
+	}{
+		{"plain", &nvimMenu{name: "Save"}, "Save"},
+		{"shortcut", &nvimMenu{name: "Save As", shortcut: "A"}, "Save &As"},
+		{"ampersand", &nvimMenu{name: "Find & Replace", shortcut: "R"}, "Find && &Replace"},
+		{"missing shortcut", &nvimMenu{name: "Save", shortcut: "x"}, "Save"},
+		{"actext", &nvimMenu{name: "Save", shortcut: "S", actext: ":w"}, "&Save\t:w"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := menuLabel(tt.item); got != tt.want {
+				t.Errorf("menuLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMenuModeOf(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"normal", "n"},
+		{"visual", "v"},
+		{"visual_select", "s"},
+		{"operator", "o"},
+		{"insert", "i"},
+		{"replace", "i"},
+		{"cmdline_normal", "c"},
+		{"terminal", "tl"},
+		{"terminal-input", "tl"},
+		{"more", ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.mode, func(t *testing.T) {
+			if got := menuModeOf(tt.mode); got != tt.want {
+				t.Errorf("menuModeOf(%q) = %q, want %q", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package editor
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"strings"
 	"time"
 
@@ -271,13 +272,19 @@ func (m *Message) resizeMessages() bool {
 func (m *Message) msgShow(args []interface{}) {
 	prevKind := ""
 	isActiveState := editor.window.IsActiveWindow()
-	notifyText := ""
 
 	for _, arg := range args {
+		notifyText := ""
 		kind, ok := arg.([]interface{})[0].(string)
 		if !ok {
 			continue
 		}
+		if isDialogKind(kind) {
+			content, _ := arg.([]interface{})[1].([]interface{})
+			if m.ws.showDialog(kind, chunksText(content)) {
+				continue
+			}
+		}
 		// text := ""
 		var buffer bytes.Buffer
 		length := 0
@@ -306,11 +313,12 @@ func (m *Message) msgShow(args []interface{}) {
 			if !ok {
 				continue
 			}
-			var color *RGBA
-			if m.ws.screen.hlAttrDef[attrId] != nil {
-				color = (m.ws.screen.hlAttrDef[attrId]).foreground
-			} else {
-				color = m.ws.foreground
+			hl := m.ws.screen.hlAttrDef[attrId]
+			if hl == nil {
+				hl = &Highlight{
+					foreground: m.ws.foreground,
+					background: m.ws.background,
+				}
 			}
 			if msg == "" || msg == "\n" || msg == "\r\n" {
 				continue
@@ -332,22 +340,22 @@ func (m *Message) msgShow(args []interface{}) {
 						cBuffer.WriteString(`<br>`)
 						lineLen = 0
 					}
-					cBuffer.WriteString(string(c))
+					cBuffer.WriteString(html.EscapeString(string(c)))
 				}
 				msg = cBuffer.String()
+			} else {
+				msg = html.EscapeString(msg)
 			}
 
 			msg = strings.Replace(msg, "\r\n", `<br>`, -1)
 			msg = strings.Replace(msg, "\n", `<br>`, -1)
 			msg = strings.Replace(msg, " ", `&nbsp;`, -1)
-			formattedMsg := fmt.Sprintf("<font color='%s'>%s</font>", warpColor(color, -20).Hex(), msg)
-			buffer.WriteString(formattedMsg)
+			buffer.WriteString(hl.toHTML(msg, m.ws.background))
 		}
 
 		// If window is minimize, then message notified as a desktop notifications
-		if !isActiveState && notifyText != "" {
-			editor.sysTray.ShowMessage("GoNeovim", notifyText, widgets.QSystemTrayIcon__NoIcon, 2000)
-			return
+		if !isActiveState && notifyText != "" && editor.escalateMessage(kind, notifyText) {
+			continue
 		}
 
 		replaceLast := false
@@ -403,6 +411,7 @@ func (m *Message) makeMessage(kind string, attrId int, text string, length int,
 }
 
 func (m *Message) msgClear() {
+	m.ws.hideDialog()
 	for _, item := range m.items {
 		item.hide()
 	}
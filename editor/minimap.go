@@ -3,15 +3,14 @@ package editor
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
 	"math"
-	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
-	"github.com/akiyosi/goneovim/util"
-	"github.com/neovim/go-client/nvim"
 	"github.com/therecipe/qt/core"
 	"github.com/therecipe/qt/gui"
 	"github.com/therecipe/qt/widgets"
@@ -23,34 +22,182 @@ type miniMapSignal struct {
 	_ func() `signal:"redrawSignal"`
 }
 
+const (
+	// minimapSyntaxBudget is the time in milliseconds nvim may spend on
+	// syntax highlights for the minimap
+	minimapSyntaxBudget = 20
+	// minimapSyncDelay is the time the minimap waits for editing to pause
+	// before it is synchronized with the buffer
+	minimapSyncDelay = 150 * time.Millisecond
+)
+
 // MiniMap is
 type MiniMap struct {
 	Screen
 
 	visible bool
 
-	curRegion   *widgets.QWidget
-	currBuf     string
-	colorscheme string
+	curRegion *widgets.QWidget
+	currBuf   string
+
+	isProcessSync bool
+	isSyncPending bool
+	isRefreshGit  bool
+	syncTimer     *time.Timer
 
-	isSetColorscheme bool
-	isProcessSync    bool
+	markers      *bufferMarkers
+	markersByRow map[int][]bufferMarker
 
-	mu            sync.Mutex
-	signal        *miniMapSignal
-	redrawUpdates chan [][]interface{}
-	stopOnce      sync.Once
-	stop          chan struct{}
+	mu             sync.Mutex
+	signal         *miniMapSignal
+	contentUpdates chan *minimapContent
 
-	nvim       *nvim.Nvim
-	uiAttached bool
-	api5       bool
-	rows       int
-	cols       int
+	rows    int
+	cols    int
+	topLine int
 
 	viewport [4]int
 }
 
+// minimapContent is a range of lines of the current buffer along with
+// their highlights, fetched from the neovim of the workspace.
+type minimapContent struct {
+	Top       int            `msgpack:"top"`
+	LineCount int            `msgpack:"line_count"`
+	Tabstop   int            `msgpack:"tabstop"`
+	Lines     []string       `msgpack:"lines"`
+	Spans     [][][3]int     `msgpack:"spans"`
+	Colors    map[string]int `msgpack:"colors"`
+
+	markers *bufferMarkers
+}
+
+// minimapContentLua returns the lines of the current buffer from the given top
+// line, cut at cols characters, with spans of byte columns and highlight ids
+// for each line. Highlights are taken from treesitter if it is active for the
+// buffer, otherwise from syntax, and then from the highlighted extmarks of all
+// namespaces. As synID() is slow, syntax is only asked at the start of each
+// run of word, space or punctuation characters, and the lines left after
+// minimapSyntaxBudget milliseconds are not highlighted by syntax.
+const minimapContentLua = `
+local top, rows, cols, budget = ...
+if vim.api.nvim_win_get_config(0).relative ~= '' then
+  return nil
+end
+local buf = vim.api.nvim_get_current_buf()
+local line_count = vim.api.nvim_buf_line_count(buf)
+if top < 0 then
+  top = vim.api.nvim_win_get_cursor(0)[1] - 1 - math.floor(rows / 2)
+end
+top = math.max(0, math.min(top, line_count - rows))
+local bottom = math.min(top + rows, line_count)
+local lines = vim.api.nvim_buf_get_lines(buf, top, bottom, false)
+local spans = {}
+for i = 1, #lines do
+  lines[i] = vim.fn.strcharpart(lines[i], 0, cols)
+  spans[i] = {}
+end
+
+local ids = {}
+local function add(row, s, e, id)
+  if id == 0 or row < top or row >= bottom then
+    return
+  end
+  local width = #lines[row - top + 1]
+  if s >= width or s >= e then
+    return
+  end
+  table.insert(spans[row - top + 1], {s, math.min(e, width), id})
+  ids[id] = true
+end
+
+local highlighted = false
+local highlighter = vim.treesitter and vim.treesitter.highlighter and vim.treesitter.highlighter.active[buf]
+if highlighter then
+  highlighted = pcall(function()
+    highlighter.tree:for_each_tree(function(tstree, tree)
+      if not tstree then
+        return
+      end
+      local query = highlighter:get_query(tree:lang()):query()
+      if not query then
+        return
+      end
+      for capture, node in query:iter_captures(tstree:root(), buf, top, bottom) do
+        local id = vim.api.nvim_get_hl_id_by_name('@' .. query.captures[capture])
+        local sr, sc, er, ec = node:range()
+        for row = math.max(sr, top), math.min(er, bottom - 1) do
+          add(row, row == sr and sc or 0, row == er and ec or math.huge, id)
+        end
+      end
+    end)
+  end)
+end
+if not highlighted and vim.b.current_syntax then
+  local hrtime = (vim.uv or vim.loop).hrtime
+  local deadline = hrtime() + budget * 1000000
+  local runs = {'^[%w_\128-\255]+', '^%s+', '^[^%w_%s\128-\255]+'}
+  for i, line in ipairs(lines) do
+    if hrtime() > deadline then
+      break
+    end
+    local lnum = top + i
+    local start, prev = 0, 0
+    local col = 1
+    while col <= #line do
+      local id = vim.fn.synIDtrans(vim.fn.synID(lnum, col, 1))
+      if id ~= prev then
+        add(lnum - 1, start, col - 1, prev)
+        start, prev = col - 1, id
+      end
+      local e = col
+      for _, run in ipairs(runs) do
+        local _, run_end = line:find(run, col)
+        if run_end then
+          e = run_end
+          break
+        end
+      end
+      col = e + 1
+    end
+    add(lnum - 1, start, #line, prev)
+  end
+end
+
+pcall(function()
+  for _, ns in pairs(vim.api.nvim_get_namespaces()) do
+    local marks = vim.api.nvim_buf_get_extmarks(buf, ns, {top, 0}, {bottom - 1, -1}, {details = true})
+    for _, mark in ipairs(marks) do
+      local row, col, details = mark[2], mark[3], mark[4]
+      if details.hl_group then
+        local id = vim.api.nvim_get_hl_id_by_name(details.hl_group)
+        local end_row = details.end_row or row
+        for r = row, math.min(end_row, bottom - 1) do
+          add(r, r == row and col or 0, (r == end_row and details.end_col) or math.huge, id)
+        end
+      end
+    end
+  end
+end)
+
+local colors = vim.empty_dict()
+for id in pairs(ids) do
+  local ok, hl = pcall(vim.api.nvim_get_hl_by_id, id, true)
+  if ok and hl.foreground then
+    colors[tostring(id)] = hl.foreground
+  end
+end
+
+return {
+  top = top + 1,
+  line_count = line_count,
+  tabstop = vim.bo[buf].tabstop,
+  lines = lines,
+  spans = spans,
+  colors = colors,
+}
+`
+
 func newMiniMap() *MiniMap {
 	widget := widgets.NewQWidget(nil, 0)
 	widget.SetContentsMargins(0, 0, 0, 0)
@@ -71,16 +218,17 @@ func newMiniMap() *MiniMap {
 			windows:        sync.Map{},
 			cursor:         [2]int{0, 0},
 			highlightGroup: make(map[string]int),
+			hlAttrDef:      minimapHighlights(nil),
 		},
-		visible:       editor.config.MiniMap.Visible,
-		curRegion:     curRegion,
-		stop:          make(chan struct{}),
-		signal:        NewMiniMapSignal(nil),
-		redrawUpdates: make(chan [][]interface{}, 1000),
+		visible:        editor.config.MiniMap.Visible,
+		curRegion:      curRegion,
+		signal:         NewMiniMapSignal(nil),
+		contentUpdates: make(chan *minimapContent, 1000),
+		topLine:        -1,
 	}
 	m.signal.ConnectRedrawSignal(func() {
-		updates := <-m.redrawUpdates
-		m.handleRedraw(updates)
+		content := <-m.contentUpdates
+		m.setContent(content)
 	})
 	m.signal.ConnectStopSignal(func() {
 	})
@@ -104,102 +252,6 @@ func newMiniMap() *MiniMap {
 	return m
 }
 
-func (m *MiniMap) startMinimapProc() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	var neovim *nvim.Nvim
-	var err error
-	minimapProcessArgs := nvim.ChildProcessArgs("-u", "NONE", "-n", "--embed", "--headless")
-	if editor.opts.Nvim != "" {
-		// Attaching to /path/to/nvim
-		childProcessCmd := nvim.ChildProcessCommand(editor.opts.Nvim)
-		neovim, err = nvim.NewChildProcess(minimapProcessArgs, childProcessCmd)
-	} else {
-		// Attaching to nvim normally
-		neovim, err = nvim.NewChildProcess(minimapProcessArgs)
-	}
-	if err != nil {
-		fmt.Println(err)
-	}
-	m.nvim = neovim
-	m.nvim.RegisterHandler("redraw", func(updates ...[]interface{}) {
-		m.redrawUpdates <- updates
-		m.signal.RedrawSignal()
-	})
-	m.width = m.widget.Width()
-	m.height = m.widget.Height()
-
-	m.updateSize()
-
-	go func() {
-		err = neovim.Serve()
-		if err != nil {
-			fmt.Println(err)
-		}
-		m.stopOnce.Do(func() {
-			close(m.stop)
-		})
-		m.signal.StopSignal()
-	}()
-
-	err = m.nvim.AttachUI(m.cols, m.rows, m.attachUIOption())
-	if err != nil {
-		fmt.Println(err)
-	}
-	m.uiAttached = true
-
-	// neovim.Subscribe("Gui")
-	neovim.Command(":syntax on")
-	neovim.Command(":set nobackup noswapfile mouse=nv laststatus=0 noruler nowrap noshowmode virtualedit+=all ts=4")
-}
-
-func (m *MiniMap) exit() {
-	go m.nvim.Command(":q!")
-}
-
-func (m *MiniMap) attachUIOption() map[string]interface{} {
-	o := make(map[string]interface{})
-	o["rgb"] = true
-	o["ext_linegrid"] = true
-
-	apiInfo, err := m.nvim.APIInfo()
-	if err == nil {
-		for _, item := range apiInfo {
-			i, ok := item.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			for k, v := range i {
-				if k != "ui_events" {
-					continue
-				}
-				events, ok := v.([]interface{})
-				if !ok {
-					continue
-				}
-				for _, event := range events {
-					function, ok := event.(map[string]interface{})
-					if !ok {
-						continue
-					}
-					name, ok := function["name"]
-					if !ok {
-						continue
-					}
-
-					switch name {
-					case "win_viewport":
-						m.api5 = true
-					}
-				}
-			}
-		}
-	}
-
-	return o
-}
-
 func (m *MiniMap) setColor() {
 	c := editor.colors.fg
 	m.curRegion.SetStyleSheet(fmt.Sprintf(" * { background-color: rgba(%d, %d, %d, 0.1);}", c.R, c.G, c.B))
@@ -214,10 +266,6 @@ func (m *MiniMap) setCurrentRegion() {
 }
 
 func (m *MiniMap) toggle() {
-	win, ok := m.getWindow(1)
-	if !ok {
-		return
-	}
 	m.mu.Lock()
 	if m.visible {
 		m.visible = false
@@ -225,9 +273,8 @@ func (m *MiniMap) toggle() {
 		m.visible = true
 	}
 	m.mu.Unlock()
-	m.curRegion.SetParent(win)
+	m.setCurrentRegion()
 	m.bufUpdate()
-	m.bufSync()
 	m.ws.updateSize()
 }
 
@@ -266,117 +313,57 @@ func (m *MiniMap) updateCols() bool {
 func (m *MiniMap) updateSize() {
 	isColDiff := m.updateCols()
 	isRowDiff := m.updateRows()
-	isTryResize := isColDiff || isRowDiff
-	if m.uiAttached && isTryResize {
-		m.nvim.TryResizeUI(m.cols, m.rows)
+	if isColDiff || isRowDiff {
+		go m.bufSync()
 	}
 }
 
 func (m *MiniMap) bufUpdate() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	if strings.Contains(m.ws.filepath, "[denite]") {
+		m.mu.Unlock()
 		return
 	}
 	if !m.visible {
 		m.widget.Hide()
+		m.mu.Unlock()
 		return
 	}
-	if m.ws.nvim == nil || m.nvim == nil {
-		return
-	}
-	m.setColorscheme()
 	m.widget.Show()
 
-	if m.currBuf == m.ws.filepath {
-		return
-	}
-	m.currBuf = m.ws.filepath
+	// Compare with git HEAD again after switching buffers or writing
+	m.isRefreshGit = true
 
-	if m.currBuf == "" {
-		go m.nvim.Command(":e! [No Name]")
-	} else {
-		go m.nvim.Command(":e! " + m.currBuf)
+	// Center the new buffer on its cursor line
+	if m.currBuf != m.ws.filepath {
+		m.currBuf = m.ws.filepath
+		m.topLine = -1
 	}
-	m.mapScroll()
+	m.mu.Unlock()
+
+	go m.bufSync()
 }
 
-func (m *MiniMap) setColorscheme() {
-	if m.isSetColorscheme {
-		return
-	}
-	colo, _ := m.ws.nvim.CommandOutput("colo")
+// follow scrolls the minimap so that it contains the viewport of the current window.
+// It reports whether the minimap needs to be synchronized.
+func (m *MiniMap) follow(topLine, botLine, currLine int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	sep := "/"
-	switch runtime.GOOS {
-	case "windows":
-		sep = `\`
-	default:
+	if m.rows == 0 || m.topLine < 0 {
+		return false
 	}
-
-	runtimePaths, _ := m.ws.nvim.RuntimePaths()
-	runtimeDir := ""
-	colorschemePath := ""
-	treesitterPath := ""
-	for _, path := range runtimePaths {
-		lsDirs, err := ioutil.ReadDir(path)
-		if err != nil {
-			continue
-		}
-		if strings.Contains(path, "nvim-treesitter") {
-			if treesitterPath == "" || len(treesitterPath) > len(path) {
-				treesitterPath = path
-			}
-		}
-		for _, d := range lsDirs {
-			dirname := d.Name()
-			finfo, err := os.Stat(path + sep + dirname)
-			if err != nil {
-				continue
-			}
-			if finfo.IsDir() {
-				packDirs, _ := ioutil.ReadDir(path + sep + dirname)
-				for _, p := range packDirs {
-					plugname := p.Name()
-					if strings.Contains(plugname, colo) {
-						runtimeDir = path
-						colorschemePath = path + sep + dirname + sep + plugname
-						break
-					}
-				}
-				if colorschemePath != "" {
-					continue
-				}
-			}
-		}
+	mapBottom := m.topLine + m.rows - 1
+	if topLine >= m.topLine && botLine <= mapBottom {
+		return false
 	}
-
-	// set runtimepath
-	m.nvim.Command("set runtimepath^=" + runtimeDir)
-
-	if m.colorscheme == colo {
-		return
+	m.topLine = currLine - m.rows/2
+	if m.topLine < 1 {
+		m.topLine = 1
 	}
-	// set colorscheme
-	m.nvim.Command(":colorscheme " + colo)
-	m.colorscheme = colo
-	m.isSetColorscheme = true
-
-	editor.putLog("detected treesitter runtime path:", treesitterPath)
-
-	// if nvim-treesitter is installed
-	// m.nvim.Command("luafile ~/.local/share/nvim/site/pack/packer/start/nvim-treesitter/lua/nvim-treesitter.lua")
-	// m.nvim.Command("luafile ~/.local/share/nvim/site/pack/packer/start/nvim-treesitter/lua/nvim-treesitter/highlight.lua")
-	// m.nvim.Command("luafile ~/.local/share/nvim/site/pack/packer/start/nvim-treesitter/lua/nvim-treesitter/configs.lua")
-	// m.nvim.Command("source ~/.local/share/nvim/site/pack/packer/start/nvim-treesitter/plugin//nvim-treesitter.vim")
-	// m.nvim.Command("TSEnableAll highlight")
-	m.nvim.Command("luafile " + treesitterPath + "/lua/nvim-treesitter.lua")
-	m.nvim.Command("luafile " + treesitterPath + "/lua/nvim-treesitter/highlight.lua")
-	m.nvim.Command("luafile " + treesitterPath + "/lua/nvim-treesitter/configs.lua")
-	m.nvim.Command("source  " + treesitterPath + "/plugin//nvim-treesitter.vim")
-	m.nvim.Command("TSEnableAll highlight")
 
+	return true
 }
 
 func (m *MiniMap) mapScroll() {
@@ -407,148 +394,249 @@ func (m *MiniMap) mapScroll() {
 	m.curRegion.Move2(0, pos)
 }
 
-func (m *MiniMap) bufSync() {
+// requestSync synchronizes the minimap once the buffer has not been changed
+// for minimapSyncDelay, so that typing does not keep nvim busy with it
+func (m *MiniMap) requestSync() {
 	m.mu.Lock()
-	defer func() {
-		m.isProcessSync = false
-		m.mu.Unlock()
-	}()
-	if m.isProcessSync {
-		return
+	defer m.mu.Unlock()
+	if m.syncTimer != nil {
+		m.syncTimer.Stop()
 	}
-	m.isProcessSync = true
+	m.syncTimer = time.AfterFunc(minimapSyncDelay, m.bufSync)
+}
 
-	if !m.visible {
-		m.widget.Hide()
+// bufSync fetches the lines and highlights shown in the minimap from the
+// current buffer and redraws the minimap with them.
+func (m *MiniMap) bufSync() {
+	m.mu.Lock()
+	if m.isProcessSync {
+		m.isSyncPending = true
+		m.mu.Unlock()
 		return
 	}
-	if m.ws.nvim == nil || m.nvim == nil {
+	if !m.visible || m.ws.nvim == nil || m.rows == 0 || m.cols == 0 {
+		m.mu.Unlock()
 		return
 	}
+	m.isProcessSync = true
+	top := m.topLine
+	if top > 0 {
+		top--
+	}
+	rows := m.rows
+	cols := m.cols
+	isRefreshGit := m.isRefreshGit
+	m.isRefreshGit = false
+	m.mu.Unlock()
 
-	// Get current window
-	win, err := m.ws.nvim.CurrentWindow()
-	if err != nil {
-		return
+	var content *minimapContent
+	err := m.ws.nvim.ExecLua(minimapContentLua, &content, top, rows, cols, minimapSyntaxBudget)
+	if err == nil && content != nil {
+		kinds := bufferMarkerKinds{
+			Diagnostics: editor.config.MiniMap.Diagnostics,
+			Search:      editor.config.MiniMap.SearchMatches,
+			Git:         editor.config.MiniMap.GitChanges,
+			RefreshGit:  isRefreshGit,
+		}
+		if kinds.Diagnostics || kinds.Search || kinds.Git {
+			content.markers, _ = fetchBufferMarkers(
+				m.ws.nvim,
+				content.Top,
+				content.Top+len(content.Lines)-1,
+				kinds,
+			)
+		}
 	}
-	// Get window config
-	config, err := m.ws.nvim.WindowConfig(win)
-	if err != nil {
-		return
+
+	m.mu.Lock()
+	m.isProcessSync = false
+	isSyncPending := m.isSyncPending
+	m.isSyncPending = false
+	m.mu.Unlock()
+
+	if err == nil && content != nil {
+		m.contentUpdates <- content
+		m.signal.RedrawSignal()
 	}
-	// if float window
-	isFloat := isWindowFloatForConfig(config)
-	if isFloat {
-		return
+	if isSyncPending {
+		m.bufSync()
 	}
+}
 
-	// Get current buffer
-	buf, err := m.ws.nvim.CurrentBuffer()
-	if err != nil {
+// setContent redraws the minimap with the fetched content
+func (m *MiniMap) setContent(content *minimapContent) {
+	m.mu.Lock()
+	rows := m.rows
+	cols := m.cols
+	m.topLine = content.Top
+	m.mu.Unlock()
+	if rows == 0 || cols == 0 {
 		return
 	}
 
-	// mmWin, ok := m.getWindow(1)
-	// if !ok {
-	// 	return
-	// }
-
-	// TODO: Rewire code based win_viewport event
-	start := 0
-	end := 0
-	var pos [4]int
-	m.ws.nvim.Eval("getpos('$')", &pos)
-	var minimapPos [4]int
-	m.nvim.Eval("getpos('$')", &minimapPos)
-	if pos[1] > minimapPos[1] {
-		end = pos[1] + 1
-	} else {
-		end = minimapPos[1] + 1
+	win, ok := m.getWindow(1)
+	if !ok || win.rows != rows || win.cols != cols {
+		m.resizeWindow(1, cols, rows)
+		win, ok = m.getWindow(1)
+		if !ok {
+			return
+		}
+		m.setCurrentRegion()
 	}
 
-	// Get buffer contents
-	replacement, err := m.ws.nvim.BufferLines(
-		buf,
-		start,
-		end,
-		false,
-	)
-	if err != nil {
+	m.hlAttrDef = minimapHighlights(content.Colors)
+	win.paintMutex.Lock()
+	win.content = minimapCells(content, m.hlAttrDef, cols, rows)
+	win.paintMutex.Unlock()
+	m.viewport[0] = content.Top
+	m.viewport[1] = content.Top + len(content.Lines) - 1
+	m.setMarkers(content.markers)
+
+	win.queueRedrawAll()
+	m.update()
+	m.mapScroll()
+}
+
+// setMarkers groups the markers by the row of the minimap they are drawn on
+func (m *MiniMap) setMarkers(markers *bufferMarkers) {
+	m.markers = markers
+	m.markersByRow = make(map[int][]bufferMarker)
+	if markers == nil {
 		return
 	}
-	if len(replacement) < 1 {
-		return
+	for _, marker := range markers.Markers {
+		row := marker.Line - m.viewport[0]
+		m.markersByRow[row] = append(m.markersByRow[row], marker)
 	}
+}
 
-	// Get current buffer of minimap
-	minimapBuf, err := m.nvim.CurrentBuffer()
-	if err != nil {
+// drawMarkers draws the markers of row y. Git changes are drawn on the left
+// edge, diagnostics on the right edge and search matches over the whole row.
+func (m *MiniMap) drawMarkers(p *gui.QPainter, y int, width int) {
+	markers, ok := m.markersByRow[y]
+	if !ok {
 		return
 	}
-
-	// Set buffer contents
-	m.nvim.SetBufferLines(
-		minimapBuf,
-		start,
-		end,
-		false,
-		replacement,
-	)
+	font := m.font
+	top := float64(y * font.lineHeight)
+	height := float64(font.lineHeight)
+	var diagnostic *bufferMarker
+	for i, marker := range markers {
+		color := m.markers.markerColor(marker.Kind)
+		switch marker.Kind {
+		case "add", "change":
+			p.FillRect5(0, int(top), 2, font.lineHeight, color.QColor())
+		case "delete":
+			p.FillRect5(0, int(top), 4, int(math.Max(1, height/2)), color.QColor())
+		case "search":
+			p.FillRect4(
+				core.NewQRectF4(0, top, float64(width), height),
+				gui.NewQColor3(color.R, color.G, color.B, 90),
+			)
+		default:
+			if diagnostic == nil || markerPriority(marker.Kind) > markerPriority(diagnostic.Kind) {
+				diagnostic = &markers[i]
+			}
+		}
+	}
+	if diagnostic != nil {
+		color := m.markers.markerColor(diagnostic.Kind)
+		p.FillRect5(width-3, int(top), 3, font.lineHeight, color.QColor())
+	}
 }
 
-func isWindowFloatForConfig(wc *nvim.WindowConfig) bool {
-	if wc == nil {
-		return false
+// minimapHighlights returns the highlights for the foreground colors of highlight ids
+func minimapHighlights(colors map[string]int) map[int]*Highlight {
+	highlights := map[int]*Highlight{
+		0: {
+			foreground: editor.colors.fg,
+			background: editor.colors.bg,
+		},
 	}
-
-	if wc.Relative == "" {
-		return false
+	for key, color := range colors {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		highlights[id] = &Highlight{
+			id:         id,
+			foreground: calcColor(color),
+			background: editor.colors.bg,
+		}
 	}
 
-	return true
+	return highlights
 }
 
-func (m *MiniMap) handleRedraw(updates [][]interface{}) {
-	for _, update := range updates {
-		event := update[0].(string)
-		args := update[1:]
-		switch event {
-		case "grid_resize":
-			m.gridResize(args)
-		// case "default_colors_set":
-		// 	args := update[1].([]interface{})
-		// 	w.setColorsSet(args)
-		case "hl_attr_define":
-			m.setHlAttrDef(args)
-		case "hl_group_set":
-			m.setHighlightGroup(args)
-			m.setColor()
-		case "grid_line":
-			m.gridLine(args)
-		case "grid_clear":
-			m.gridClear(args)
-		case "grid_destroy":
-			m.gridDestroy(args)
-		case "grid_cursor_goto":
-			// m.gridCursorGoto(args)
-		case "grid_scroll":
-			m.gridScroll(args)
-
-		case "win_viewport":
-			vp := args[0].([]interface{})
-			m.viewport = [4]int{
-				util.ReflectToInt(vp[2]) + 1,
-				util.ReflectToInt(vp[3]) + 1,
-				util.ReflectToInt(vp[4]) + 1,
-				util.ReflectToInt(vp[5]) + 1,
+// minimapCells lays out the fetched lines in a grid of cols x rows,
+// expanding tabs and applying the highlight of each byte column.
+func minimapCells(content *minimapContent, highlights map[int]*Highlight, cols, rows int) [][]*Cell {
+	ts := content.Tabstop
+	if ts <= 0 {
+		ts = 8
+	}
+	cells := make([][]*Cell, rows)
+	backing := make([]Cell, 0, rows*cols)
+	var ids []int
+	for y := 0; y < rows; y++ {
+		cells[y] = make([]*Cell, cols)
+		if y >= len(content.Lines) {
+			continue
+		}
+		line := content.Lines[y]
+		// Every character takes at least one column
+		n := 0
+		for i := range line {
+			if n == cols {
+				line = line[:i]
+				break
+			}
+			n++
+		}
+
+		if cap(ids) < len(line) {
+			ids = make([]int, len(line))
+		}
+		ids = ids[:len(line)]
+		for i := range ids {
+			ids[i] = 0
+		}
+		if y < len(content.Spans) {
+			for _, span := range content.Spans[y] {
+				for i := span[0]; i < span[1] && i < len(line); i++ {
+					if i >= 0 {
+						ids[i] = span[2]
+					}
+				}
 			}
-		case "flush":
-			m.update()
-			m.mapScroll()
+		}
 
-		default:
+		x := 0
+		for i := 0; i < len(line) && x < cols; {
+			r, size := utf8.DecodeRuneInString(line[i:])
+			switch r {
+			case '\t':
+				x += ts - x%ts
+			case ' ':
+				x++
+			default:
+				highlight, ok := highlights[ids[i]]
+				if !ok {
+					highlight = highlights[0]
+				}
+				backing = append(backing, Cell{
+					normalWidth: true,
+					char:        line[i : i+size],
+					highlight:   highlight,
+				})
+				cells[y][x] = &backing[len(backing)-1]
+				x++
+			}
+			i += size
 		}
 	}
+
+	return cells
 }
 
 func (m *MiniMap) transparent(bg *RGBA) int {
@@ -626,13 +714,18 @@ func (m *MiniMap) wheelEvent(event *gui.QWheelEvent) {
 		return
 	}
 
+	if m.topLine < 0 {
+		return
+	}
 	if vert > 0 {
-		m.nvim.Input(fmt.Sprintf("%v<C-y>", accel))
+		m.topLine -= accel
 	} else if vert < 0 {
-		m.nvim.Input(fmt.Sprintf("%v<C-e>", accel))
+		m.topLine += accel
 	}
-
-	// m.mapScroll()
+	if m.topLine < 1 {
+		m.topLine = 1
+	}
+	go m.bufSync()
 
 	event.Accept()
 }
@@ -640,16 +733,21 @@ func (m *MiniMap) wheelEvent(event *gui.QWheelEvent) {
 func (m *MiniMap) mouseEvent(event *gui.QMouseEvent) {
 	font := m.font
 	y := int(float64(event.Y()) / float64(font.lineHeight))
-	targetPos := 0
-	if m.api5 {
-		// targetPos = m.topLine + y
-		targetPos = m.viewport[0] + y
-	} else {
-		var absMapTop int
-		m.nvim.Eval("line('w0')", &absMapTop)
-		targetPos = absMapTop + y
+	targetPos := m.viewport[0] + y
+	// Jump to the position of a marker on the clicked row
+	jumped := false
+	for _, marker := range m.markersByRow[y] {
+		switch marker.Kind {
+		case "add", "change", "delete":
+			continue
+		}
+		m.ws.nvim.Command(fmt.Sprintf("call cursor(%d, %d)", marker.Line, marker.Col+1))
+		jumped = true
+		break
+	}
+	if !jumped {
+		m.ws.nvim.Command(fmt.Sprintf("%d", targetPos))
 	}
-	m.ws.nvim.Command(fmt.Sprintf("%d", targetPos))
 
 	mappings, err := m.ws.nvim.KeyMap("normal")
 	if err != nil {
@@ -752,4 +850,8 @@ func (w *Window) drawMinimap(p *gui.QPainter, y int, col int, cols int) {
 		}
 
 	}
+
+	if w.s.ws.minimap != nil {
+		w.s.ws.minimap.drawMarkers(p, y, w.Width())
+	}
 }
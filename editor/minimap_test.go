@@ -0,0 +1,134 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMinimapCells(t *testing.T) {
+	normal := &Highlight{id: 0}
+	keyword := &Highlight{id: 5}
+	highlights := map[int]*Highlight{0: normal, 5: keyword}
+
+	content := &minimapContent{
+		Top:     1,
+		Tabstop: 4,
+		Lines:   []string{"if x", "\tab", "abcdefgh", "あいうえおかき"},
+		Spans:   [][][3]int{{{0, 2, 5}}, {}, {{0, 8, 9}}, {}},
+	}
+	cells := minimapCells(content, highlights, 6, 5)
+
+	tests := []struct {
+		name      string
+		row       int
+		col       int
+		char      string
+		highlight *Highlight
+	}{
+		{"minimapCells() span is highlighted", 0, 0, "i", keyword},
+		{"minimapCells() text after span is normal", 0, 3, "x", normal},
+		{"minimapCells() space is empty", 0, 2, "", nil},
+		{"minimapCells() tab is expanded", 1, 4, "a", normal},
+		{"minimapCells() unknown highlight is normal", 2, 0, "a", normal},
+		{"minimapCells() line is cut at cols", 2, 5, "f", normal},
+		{"minimapCells() multibyte line is cut at cols", 3, 5, "か", normal},
+		{"minimapCells() row without line is empty", 4, 0, "", nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cell := cells[tt.row][tt.col]
+			if tt.char == "" {
+				if cell != nil {
+					t.Errorf("minimapCells() = %v, want nil", cell.char)
+				}
+				return
+			}
+			if cell == nil {
+				t.Fatalf("minimapCells() = nil, want %v", tt.char)
+			}
+			if cell.char != tt.char || cell.highlight != tt.highlight {
+				t.Errorf("minimapCells() = %v %v, want %v %v", cell.char, cell.highlight, tt.char, tt.highlight)
+			}
+		})
+	}
+	if len(cells) != 5 || len(cells[0]) != 6 {
+		t.Errorf("minimapCells() size = %dx%d, want 6x5", len(cells[0]), len(cells))
+	}
+}
+
+// newMinimapBenchContent returns the content fetched for a minimap of rows
+// lines from a large file, with a highlight span for every word.
+func newMinimapBenchContent(rows, lineLength int) *minimapContent {
+	content := &minimapContent{
+		Top:       1,
+		LineCount: 100000,
+		Tabstop:   8,
+	}
+	word := "func(x) "
+	for y := 0; y < rows; y++ {
+		line := "\t" + strings.Repeat(word, lineLength/len(word))
+		var spans [][3]int
+		for x := 1; x+len(word) <= len(line); x += len(word) {
+			spans = append(spans, [3]int{x, x + 4, x%7 + 1})
+		}
+		content.Lines = append(content.Lines, line)
+		content.Spans = append(content.Spans, spans)
+	}
+
+	return content
+}
+
+func BenchmarkMinimapCells(b *testing.B) {
+	highlights := map[int]*Highlight{}
+	for id := 0; id < 8; id++ {
+		highlights[id] = &Highlight{id: id}
+	}
+	for _, bm := range []struct {
+		rows       int
+		lineLength int
+	}{
+		{200, 80},
+		{1000, 120},
+		{1000, 10000},
+	} {
+		content := newMinimapBenchContent(bm.rows, bm.lineLength)
+		b.Run(fmt.Sprintf("rows=%d/length=%d", bm.rows, bm.lineLength), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				minimapCells(content, highlights, 110, bm.rows)
+			}
+		})
+	}
+}
+
+// BenchmarkMinimapContentLua measures the time nvim takes to return the
+// content of the minimap from a large syntax highlighted file
+func BenchmarkMinimapContentLua(b *testing.B) {
+	neovim := startTestNvim(b)
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("local v%d = function(a, b) return a + b .. \"%s\" end -- comment", i, strings.Repeat("x", i%80))
+	}
+	setTestBuffer(b, neovim, lines)
+	if err := neovim.Command("syntax on | set filetype=lua"); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, rows := range []int{200, 1000} {
+		rows := rows
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var content *minimapContent
+				err := neovim.ExecLua(minimapContentLua, &content, 5000, rows, 110, minimapSyntaxBudget)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if content == nil || len(content.Lines) != rows {
+					b.Fatalf("minimapContentLua returned %v", content)
+				}
+			}
+		})
+	}
+}
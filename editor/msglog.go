@@ -0,0 +1,431 @@
+package editor
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// messageLogKinds are the kinds selectable in the filter of the message log.
+// An empty kind matches every entry.
+var messageLogKinds = []string{
+	"",
+	"emsg",
+	"echoerr",
+	"lua_error",
+	"rpc_error",
+	"wmsg",
+	"echo",
+	"echomsg",
+	"lua_print",
+	"search_count",
+	"quickfix",
+	"return_prompt",
+	"confirm",
+	"confirm_sub",
+}
+
+// MessageLogEntry is a message sent by msg_show
+type MessageLogEntry struct {
+	Kind string    `json:"kind"`
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+}
+
+// MessageLog is the panel which lists the messages of the active workspace
+type MessageLog struct {
+	widget   *widgets.QWidget
+	kind     *widgets.QComboBox
+	search   *widgets.QLineEdit
+	list     *widgets.QListWidget
+	dock     *widgets.QPushButton
+	isShown  bool
+	isFloat  bool
+	filtered []*MessageLogEntry
+}
+
+func newMessageLog() *MessageLog {
+	widget := widgets.NewQWidget(nil, 0)
+	widget.SetContentsMargins(0, 0, 0, 0)
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(6, 6, 6, 6)
+	layout.SetSpacing(4)
+	widget.SetLayout(layout)
+
+	kind := widgets.NewQComboBox(nil)
+	for _, k := range messageLogKinds {
+		if k == "" {
+			kind.AddItem("all", core.NewQVariant())
+			continue
+		}
+		kind.AddItem(k, core.NewQVariant())
+	}
+	kind.SetFocusPolicy(core.Qt__ClickFocus)
+
+	search := widgets.NewQLineEdit(nil)
+	search.SetPlaceholderText("Search")
+	search.SetClearButtonEnabled(true)
+
+	toolbar := widgets.NewQHBoxLayout()
+	toolbar.SetContentsMargins(0, 0, 0, 0)
+	toolbar.SetSpacing(4)
+	toolbar.AddWidget(kind, 0, 0)
+	toolbar.AddWidget(search, 1, 0)
+
+	list := widgets.NewQListWidget(nil)
+	list.SetFrameShape(widgets.QFrame__NoFrame)
+	list.SetSelectionMode(widgets.QAbstractItemView__ExtendedSelection)
+	list.SetWordWrap(true)
+	list.SetHorizontalScrollBarPolicy(core.Qt__ScrollBarAlwaysOff)
+
+	buttons := widgets.NewQHBoxLayout()
+	buttons.SetContentsMargins(0, 0, 0, 0)
+	buttons.SetSpacing(4)
+	copyButton := widgets.NewQPushButton2("Copy", nil)
+	clearButton := widgets.NewQPushButton2("Clear", nil)
+	dock := widgets.NewQPushButton2("Float", nil)
+	closeButton := widgets.NewQPushButton2("Close", nil)
+	for _, b := range []*widgets.QPushButton{copyButton, clearButton, dock, closeButton} {
+		b.SetFocusPolicy(core.Qt__NoFocus)
+	}
+	buttons.AddWidget(copyButton, 0, 0)
+	buttons.AddWidget(clearButton, 0, 0)
+	buttons.AddStretch(1)
+	buttons.AddWidget(dock, 0, 0)
+	buttons.AddWidget(closeButton, 0, 0)
+
+	layout.AddLayout(toolbar, 0)
+	layout.AddWidget(list, 1, 0)
+	layout.AddLayout(buttons, 0)
+
+	l := &MessageLog{
+		widget: widget,
+		kind:   kind,
+		search: search,
+		list:   list,
+		dock:   dock,
+	}
+
+	kind.ConnectCurrentIndexChanged(func(int) {
+		l.refresh()
+	})
+	search.ConnectTextChanged(func(string) {
+		l.refresh()
+	})
+	copyButton.ConnectClicked(func(bool) {
+		l.copy()
+	})
+	clearButton.ConnectClicked(func(bool) {
+		l.clear()
+	})
+	dock.ConnectClicked(func(bool) {
+		l.toggleFloat()
+	})
+	closeButton.ConnectClicked(func(bool) {
+		l.hide()
+	})
+	// Closing the floating window only hides the panel
+	widget.ConnectCloseEvent(func(event *gui.QCloseEvent) {
+		l.isShown = false
+		event.Accept()
+	})
+
+	return l
+}
+
+// logMessages keeps the msg_show messages of the workspace for the message log
+func (w *Workspace) logMessages(args []interface{}) {
+	for _, arg := range args {
+		a, ok := arg.([]interface{})
+		if !ok || len(a) < 2 {
+			continue
+		}
+		kind, _ := a[0].(string)
+		content, _ := a[1].([]interface{})
+		text := strings.TrimRight(chunksText(content), "\r\n")
+		if text == "" {
+			continue
+		}
+		w.addMessageLogEntry(&MessageLogEntry{
+			Kind: kind,
+			Text: text,
+			Time: time.Now(),
+		})
+	}
+}
+
+func (w *Workspace) addMessageLogEntry(entry *MessageLogEntry) {
+	w.msgLog = append(w.msgLog, entry)
+	var dropped []*MessageLogEntry
+	if over := len(w.msgLog) - editor.config.MessageLog.MaxEntries; over > 0 {
+		dropped = w.msgLog[:over]
+		w.msgLog = w.msgLog[over:]
+	}
+	if editor.msgLog != nil && editor.workspaces[editor.active] == w {
+		editor.msgLog.appendEntry(entry, dropped)
+	}
+}
+
+// filterMessageLog returns the entries of the kind which contain the text.
+// The text is matched case insensitively.
+func filterMessageLog(entries []*MessageLogEntry, kind, text string) []*MessageLogEntry {
+	text = strings.ToLower(text)
+	filtered := []*MessageLogEntry{}
+	for _, e := range entries {
+		if e.matches(kind, text) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+// matches reports whether the entry is of the kind and contains the lower
+// case text
+func (e *MessageLogEntry) matches(kind, text string) bool {
+	if kind != "" && e.Kind != kind {
+		return false
+	}
+
+	return text == "" || strings.Contains(strings.ToLower(e.Text), text)
+}
+
+func (e *MessageLogEntry) String() string {
+	kind := e.Kind
+	if kind == "" {
+		kind = "-"
+	}
+
+	return fmt.Sprintf("%s [%s] %s", e.Time.Format("15:04:05"), kind, e.Text)
+}
+
+func (e *Editor) toggleMessageLog() {
+	if e.msgLog == nil {
+		e.msgLog = newMessageLog()
+		e.msgLog.widget.Hide()
+		e.splitter.AddWidget(e.msgLog.widget)
+		e.msgLog.widget.Resize2(e.config.MessageLog.Width, e.msgLog.widget.Height())
+	}
+	if e.msgLog.isShown {
+		e.msgLog.hide()
+		return
+	}
+	e.msgLog.show()
+}
+
+func (l *MessageLog) show() {
+	l.isShown = true
+	l.setColor()
+	l.refresh()
+	l.widget.Show()
+}
+
+func (l *MessageLog) hide() {
+	l.widget.Hide()
+	l.isShown = false
+}
+
+// toggleFloat detaches the panel into its own window, or docks it back to the
+// right of the screen
+func (l *MessageLog) toggleFloat() {
+	if l.isFloat {
+		editor.splitter.AddWidget(l.widget)
+		l.dock.SetText("Float")
+		l.isFloat = false
+	} else {
+		l.widget.SetParent(nil)
+		l.widget.SetWindowFlags(core.Qt__Tool)
+		l.widget.SetWindowTitle("Messages")
+		l.widget.Resize2(editor.config.MessageLog.Width, editor.window.Height()/2)
+		l.dock.SetText("Dock")
+		l.isFloat = true
+	}
+	l.widget.Show()
+}
+
+// filterKind returns the kind selected in the filter
+func (l *MessageLog) filterKind() string {
+	if i := l.kind.CurrentIndex(); i > 0 && i < len(messageLogKinds) {
+		return messageLogKinds[i]
+	}
+
+	return messageLogKinds[0]
+}
+
+// refresh lists the entries of the active workspace again, as the filter or
+// the workspace is changed
+func (l *MessageLog) refresh() {
+	if !l.isShown {
+		return
+	}
+	ws := editor.workspaces[editor.active]
+	l.filtered = filterMessageLog(ws.msgLog, l.filterKind(), l.search.Text())
+
+	l.list.Clear()
+	for _, entry := range l.filtered {
+		l.addItem(entry)
+	}
+	l.list.ScrollToBottom()
+}
+
+// appendEntry adds a new entry of the active workspace to the list, and
+// removes the entries dropped from the workspace by MaxEntries
+func (l *MessageLog) appendEntry(entry *MessageLogEntry, dropped []*MessageLogEntry) {
+	if !l.isShown {
+		return
+	}
+	// The dropped entries are the oldest ones, so that they are listed first
+	for _, d := range dropped {
+		if len(l.filtered) == 0 || l.filtered[0] != d {
+			continue
+		}
+		l.filtered = l.filtered[1:]
+		l.list.TakeItem(0)
+	}
+	if !entry.matches(l.filterKind(), strings.ToLower(l.search.Text())) {
+		return
+	}
+	l.filtered = append(l.filtered, entry)
+	l.addItem(entry)
+	l.list.ScrollToBottom()
+}
+
+func (l *MessageLog) addItem(entry *MessageLogEntry) {
+	item := widgets.NewQListWidgetItem2(entry.String(), l.list, 0)
+	item.SetToolTip(entry.Time.Format(time.RFC1123))
+	if color := messageLogColor(entry.Kind); color != nil {
+		item.SetForeground(gui.NewQBrush3(color.QColor(), core.Qt__SolidPattern))
+	}
+}
+
+func messageLogColor(kind string) *RGBA {
+	switch kind {
+	case "emsg", "echoerr", "lua_error", "rpc_error":
+		return newRGBA(204, 62, 68, 1)
+	case "wmsg":
+		return newRGBA(203, 203, 65, 1)
+	}
+
+	return nil
+}
+
+// copy copies the selected entries to the clipboard, or all the listed entries
+// if nothing is selected
+func (l *MessageLog) copy() {
+	lines := []string{}
+	for i, entry := range l.filtered {
+		item := l.list.Item(i)
+		if item == nil || !item.IsSelected() {
+			continue
+		}
+		lines = append(lines, entry.String())
+	}
+	if len(lines) == 0 {
+		for _, entry := range l.filtered {
+			lines = append(lines, entry.String())
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	editor.app.Clipboard().SetText(strings.Join(lines, "\n"), gui.QClipboard__Clipboard)
+}
+
+func (l *MessageLog) clear() {
+	editor.workspaces[editor.active].msgLog = nil
+	l.refresh()
+}
+
+func (l *MessageLog) setColor() {
+	fg := editor.colors.widgetFg.String()
+	bg := editor.colors.widgetBg.String()
+	l.widget.SetStyleSheet(fmt.Sprintf(`
+		QWidget { color: %[1]s; background-color: %[2]s; }
+		QLineEdit, QComboBox { background-color: %[3]s; border: 0px; padding: 2px; }
+		QPushButton { background-color: %[3]s; border: 0px; padding: 3px 8px; }
+		QPushButton:hover { background-color: %[4]s; }
+		QListWidget::item:selected { background-color: %[4]s; }
+		`,
+		fg,
+		bg,
+		editor.colors.widgetInputArea.String(),
+		editor.colors.selectedBg.String(),
+	))
+}
+
+// messageLogPath returns the file which keeps the message log of the
+// workspaces opened in the directory
+func messageLogPath(cwd string) string {
+	return filepath.Join(editor.configDir, "messages", messageLogFile(cwd))
+}
+
+// messageLogFile names the file of the message log of the directory after its
+// hash, as the path can not be used as a file name as it is
+func messageLogFile(cwd string) string {
+	return fmt.Sprintf("%x.json", sha1.Sum([]byte(cwd)))
+}
+
+// loadMessageLog restores the message log saved for the directory the
+// workspace is opened in, once its directory is known. The log is saved for
+// the same directory even if the workspace moves to another one.
+func (w *Workspace) loadMessageLog() {
+	if !editor.config.MessageLog.Persist || w.cwd == "" || w.msgLogCwd != "" {
+		return
+	}
+	w.msgLogCwd = w.cwd
+	data, err := ioutil.ReadFile(messageLogPath(w.msgLogCwd))
+	if err != nil {
+		return
+	}
+	var entries []*MessageLogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		editor.putLog("failed to load the message log:", err)
+		return
+	}
+	w.msgLog = append(entries, w.msgLog...)
+	if over := len(w.msgLog) - editor.config.MessageLog.MaxEntries; over > 0 {
+		w.msgLog = w.msgLog[over:]
+	}
+	if editor.msgLog != nil && editor.active < len(editor.workspaces) && editor.workspaces[editor.active] == w {
+		editor.msgLog.refresh()
+	}
+}
+
+// saveMessageLogs saves the message log of every workspace so that they can be
+// restored when a workspace is opened in the directory again
+func (e *Editor) saveMessageLogs() {
+	for _, ws := range e.workspaces {
+		if ws != nil {
+			ws.saveMessageLog()
+		}
+	}
+}
+
+// saveMessageLog saves the message log of the workspace, which is done as well
+// when the workspace is closed
+func (w *Workspace) saveMessageLog() {
+	if !editor.config.MessageLog.Persist || w.msgLogCwd == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(editor.configDir, "messages"), 0755); err != nil {
+		editor.putLog("failed to save the message log:", err)
+		return
+	}
+	data, err := json.Marshal(w.msgLog)
+	if err != nil {
+		editor.putLog("failed to save the message log:", err)
+		return
+	}
+	if err := ioutil.WriteFile(messageLogPath(w.msgLogCwd), data, 0644); err != nil {
+		editor.putLog("failed to save the message log:", err)
+	}
+}
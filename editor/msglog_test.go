@@ -0,0 +1,59 @@
+package editor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterMessageLog(t *testing.T) {
+	emsg := &MessageLogEntry{Kind: "emsg", Text: "E492: Not an editor command: foo"}
+	wmsg := &MessageLogEntry{Kind: "wmsg", Text: "search hit BOTTOM"}
+	echo := &MessageLogEntry{Kind: "echo", Text: "Foo bar"}
+	entries := []*MessageLogEntry{emsg, wmsg, echo}
+
+	tests := []struct {
+		name string
+		kind string
+		text string
+		want []*MessageLogEntry
+	}{
+		{"all", "", "", []*MessageLogEntry{emsg, wmsg, echo}},
+		{"by kind", "wmsg", "", []*MessageLogEntry{wmsg}},
+		{"by text ignoring case", "", "FOO", []*MessageLogEntry{emsg, echo}},
+		{"by kind and text", "echo", "foo", []*MessageLogEntry{echo}},
+		{"no match", "lua_error", "", []*MessageLogEntry{}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterMessageLog(entries, tt.kind, tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterMessageLog() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageLogFile(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"same directory", "/home/user/project", "/home/user/project", true},
+		{"other directory", "/home/user/project", "/home/user/other", false},
+		{"windows path", `C:\Users\user\project`, `C:\Users\user\other`, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := messageLogFile(tt.a), messageLogFile(tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("messageLogFile(%q) = %q, messageLogFile(%q) = %q", tt.a, a, tt.b, b)
+			}
+			if strings.ContainsAny(a, `/\:`) || !strings.HasSuffix(a, ".json") {
+				t.Errorf("messageLogFile(%q) = %q, want a json file name", tt.a, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// MessageStatus is the small overlay at the bottom right of the screen which
+// shows msg_showmode, msg_showcmd and msg_ruler when the statusline does not
+// include their components
+type MessageStatus struct {
+	ws     *Workspace
+	widget *widgets.QLabel
+	texts  map[string]string
+}
+
+var messageStatusOrder = []string{"mode-message", "showcmd", "ruler"}
+
+func initMessageStatus(ws *Workspace) *MessageStatus {
+	widget := widgets.NewQLabel(ws.screen.widget, 0)
+	widget.SetTextFormat(core.Qt__RichText)
+	widget.SetContentsMargins(4, 2, 4, 2)
+	widget.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+	widget.Hide()
+
+	return &MessageStatus{
+		ws:     ws,
+		widget: widget,
+		texts:  make(map[string]string),
+	}
+}
+
+// updateMessageStatus handles the msg_showmode, msg_showcmd and msg_ruler events.
+// Only the last call of the batch matters, each one replaces the previous content.
+func (w *Workspace) updateMessageStatus(component string, args []interface{}) {
+	if len(args) == 0 {
+		return
+	}
+	arg, ok := args[len(args)-1].([]interface{})
+	if !ok || len(arg) == 0 {
+		return
+	}
+	content, _ := arg[0].([]interface{})
+	text := w.screen.chunksHTML(content)
+
+	if w.statusline != nil && w.statusline.setMessage(component, text) {
+		text = ""
+	}
+	if w.msgStatus == nil {
+		if text == "" {
+			return
+		}
+		w.msgStatus = initMessageStatus(w)
+	}
+	w.msgStatus.update(component, text)
+}
+
+func (m *MessageStatus) update(component, text string) {
+	m.texts[component] = text
+
+	content := ""
+	for _, c := range messageStatusOrder {
+		if m.texts[c] == "" {
+			continue
+		}
+		if content != "" {
+			content += "&nbsp;&nbsp;"
+		}
+		content += m.texts[c]
+	}
+	if content == "" {
+		m.widget.Hide()
+		return
+	}
+
+	m.widget.SetFont(m.ws.font.fontNew)
+	m.widget.SetStyleSheet(fmt.Sprintf(
+		" * { background-color: %s; }",
+		m.ws.background.String(),
+	))
+	m.widget.SetText(content)
+	m.widget.AdjustSize()
+	m.widget.Move2(
+		m.ws.screen.widget.Width()-m.widget.Width(),
+		m.ws.screen.widget.Height()-m.widget.Height(),
+	)
+	m.widget.Show()
+	m.widget.Raise()
+}
@@ -20,21 +20,36 @@ const (
 	NotifyInfo NotifyLevel = 0
 	// NotifyWarn is a type of "warning"
 	NotifyWarn NotifyLevel = 1
+	// NotifyError is a type of "error"
+	NotifyError NotifyLevel = 2
+	// NotifyDebug is a type of "debug"
+	NotifyDebug NotifyLevel = 3
 )
 
 // Notification is
 type Notification struct {
+	id        string
 	widget    *widgets.QWidget
+	levelIcon *svg.QSvgWidget
+	title     *widgets.QLabel
+	label     *widgets.QLabel
 	closeIcon *svg.QSvgWidget
+	timer     *core.QTimer
 	pos       *core.QPoint
 	isDrag    bool
 	isMoved   bool
 	isHide    bool
+	buttons   []*NotifyButton
+	layout    *widgets.QVBoxLayout
+	buttonRow *widgets.QWidget
 }
 
 // NotifyOptions is
 type NotifyOptions struct {
 	buttons []*NotifyButton
+	id      string
+	title   string
+	dismiss func()
 }
 
 // NotifyOptionArg is
@@ -46,6 +61,29 @@ func notifyOptionArg(b []*NotifyButton) NotifyOptionArg {
 	}
 }
 
+// notifyIDOptionArg sets the id of the notification. A notification with the
+// same id as a shown one replaces it.
+func notifyIDOptionArg(id string) NotifyOptionArg {
+	return func(option *NotifyOptions) {
+		option.id = id
+	}
+}
+
+// notifyDismissOptionArg sets the function called when the notification is
+// dropped from the notification center, and its buttons can no longer be
+// clicked
+func notifyDismissOptionArg(dismiss func()) NotifyOptionArg {
+	return func(option *NotifyOptions) {
+		option.dismiss = dismiss
+	}
+}
+
+func notifyTitleOptionArg(title string) NotifyOptionArg {
+	return func(option *NotifyOptions) {
+		option.title = title
+	}
+}
+
 func newNotification(l NotifyLevel, p int, message string, options ...NotifyOptionArg) *Notification {
 	e := editor
 
@@ -65,26 +103,26 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	levelIcon.SetFixedHeight(editor.iconSize)
 	levelIcon.SetContentsMargins(0, 0, 0, 0)
 	levelIcon.SetStyleSheet(" * {background-color: rgba(0, 0, 0, 0)}")
-	var level string
-	switch l {
-	case NotifyInfo:
-		level = e.getSvg("info", newRGBA(27, 161, 226, 1))
-	case NotifyWarn:
-		level = e.getSvg("warn", newRGBA(255, 205, 0, 1))
-	default:
-		level = e.getSvg("info", newRGBA(27, 161, 226, 1))
-	}
-	levelIcon.Load2(core.NewQByteArray2(level, len(level)))
+
+	size := int(float64(editor.workspaces[editor.active].font.width) * 1.33)
+	title := widgets.NewQLabel(nil, 0)
+	title.SetStyleSheet(" * {background-color: rgba(0, 0, 0, 0)}")
+	title.SetFont(gui.NewQFont2(editor.extFontFamily, size, int(gui.QFont__Bold), false))
+	title.Hide()
 
 	label := widgets.NewQLabel(nil, 0)
 	label.SetStyleSheet(" * {background-color: rgba(0, 0, 0, 0)}")
-	size := int(float64(editor.workspaces[editor.active].font.width) * 1.33)
 	label.SetFont(gui.NewQFont2(editor.extFontFamily, size, 1, false))
-	if utf8.RuneCountInString(message) > 50 {
-		label.SetWordWrap(true)
-	}
 	label.SetSizePolicy2(widgets.QSizePolicy__Expanding, widgets.QSizePolicy__Expanding)
-	label.SetText(message)
+
+	textWidget := widgets.NewQWidget(nil, 0)
+	textLayout := widgets.NewQVBoxLayout()
+	textLayout.SetContentsMargins(0, 0, 0, 0)
+	textLayout.SetSpacing(4)
+	textWidget.SetLayout(textLayout)
+	textWidget.SetStyleSheet(" * {background-color: rgba(0, 0, 0, 0)}")
+	textLayout.AddWidget(title, 0, 0)
+	textLayout.AddWidget(label, 0, 0)
 
 	closeIcon := svg.NewQSvgWidget(nil)
 	svgContent := e.getSvg("cross", editor.colors.widgetBg)
@@ -113,7 +151,7 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	})
 
 	messageLayout.AddWidget(levelIcon, 0, 0)
-	messageLayout.AddWidget(label, 0, 0)
+	messageLayout.AddWidget(textWidget, 0, 0)
 	messageLayout.AddWidget(closeIcon, 0, 0)
 	messageLayout.SetAlignment(levelIcon, core.Qt__AlignTop)
 	messageLayout.SetAlignment(closeIcon, core.Qt__AlignTop)
@@ -121,58 +159,19 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	layout.AddWidget(messageWidget, 0, 0)
 	layout.SetAlignment(messageWidget, core.Qt__AlignTop)
 
-	bottomwidget := widgets.NewQWidget(nil, 0)
-	bottomlayout := widgets.NewQHBoxLayout()
-	bottomlayout.SetContentsMargins(0, 0, 0, 0)
-	bottomlayout.SetSpacing(10)
-	bottomwidget.SetLayout(bottomlayout)
-
-	notification := &Notification{}
 	opts := NotifyOptions{}
 	for _, o := range options {
 		o(&opts)
 	}
-	for _, opt := range opts.buttons {
-		if opt.text != "" {
-			// * plugin install button
-			buttonLabel := widgets.NewQLabel(nil, 0)
-			buttonLabel.SetFont(gui.NewQFont2(editor.extFontFamily, editor.extFontSize-1, 1, false))
-			buttonLabel.SetFixedHeight(28)
-			buttonLabel.SetContentsMargins(10, 5, 10, 5)
-			buttonLabel.SetAlignment(core.Qt__AlignCenter)
-			button := widgets.NewQWidget(nil, 0)
-			buttonLayout := widgets.NewQHBoxLayout()
-			buttonLayout.SetContentsMargins(0, 0, 0, 0)
-			buttonLayout.AddWidget(buttonLabel, 0, 0)
-			button.SetLayout(buttonLayout)
-			button.SetObjectName("button")
-			buttonLabel.SetText(opt.text)
-			color := "#0e639c"
-			button.SetStyleSheet(fmt.Sprintf(" #button QLabel { color: #ffffff; background: %s;} ", color))
-			fn := opt.action
-			button.ConnectMousePressEvent(func(*gui.QMouseEvent) {
-				go fn()
-				notification.closeNotification()
-			})
-			button.ConnectEnterEvent(func(event *core.QEvent) {
-				hoverColor := "#1177bb"
-				button.SetStyleSheet(fmt.Sprintf(" #button QLabel { color: #ffffff; background: %s;} ", hoverColor))
-			})
-			button.ConnectLeaveEvent(func(event *core.QEvent) {
-				button.SetStyleSheet(fmt.Sprintf(" #button QLabel { color: #ffffff; background: %s;} ", color))
-			})
-			bottomlayout.AddWidget(button, 0, 0)
-			bottomlayout.SetAlignment(button, core.Qt__AlignRight)
-		}
-	}
-	if len(opts.buttons) > 0 {
-		layout.SetSpacing(8)
-		bottomlayout.SetContentsMargins(0, 0, 0, 0)
-		bottomlayout.SetSpacing(10)
-		bottomwidget.AdjustSize()
-		layout.AddWidget(bottomwidget, 0, 0)
-		layout.SetAlignment(bottomwidget, core.Qt__AlignRight)
+	notification := &Notification{
+		id:        opts.id,
+		levelIcon: levelIcon,
+		title:     title,
+		label:     label,
+		layout:    layout,
 	}
+	notification.setContent(l, opts.title, message)
+	notification.setButtons(opts.buttons)
 	layout.SetContentsMargins(10, 10, 10, 10)
 
 	isDrag := false
@@ -225,6 +224,15 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	}()
 
 	// Notification hiding
+	notification.timer = core.NewQTimer(nil)
+	notification.timer.SetSingleShot(true)
+	notification.timer.ConnectTimeout(notification.hideNotification)
+	notification.startTimer(p)
+
+	return notification
+}
+
+func (n *Notification) startTimer(p int) {
 	var displayPeriod int
 	if p < 0 { // default display period is 6 seconds
 		displayPeriod = 6
@@ -233,14 +241,122 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	} else {
 		displayPeriod = p
 	}
+	n.timer.Stop()
 	if displayPeriod > 0 {
-		timer := core.NewQTimer(nil)
-		timer.SetSingleShot(true)
-		timer.ConnectTimeout(notification.hideNotification)
-		timer.Start(displayPeriod * 1000)
+		n.timer.Start(displayPeriod * 1000)
 	}
+}
 
-	return notification
+// notifyLevelSvg returns the icon of the notification level
+func notifyLevelSvg(l NotifyLevel) string {
+	switch l {
+	case NotifyInfo:
+		return editor.getSvg("info", newRGBA(27, 161, 226, 1))
+	case NotifyWarn:
+		return editor.getSvg("warn", newRGBA(255, 205, 0, 1))
+	case NotifyError:
+		return editor.getSvg("linterr", newRGBA(204, 62, 68, 1))
+	case NotifyDebug:
+		return editor.getSvg("info", editor.colors.inactiveFg)
+	default:
+		return editor.getSvg("info", newRGBA(27, 161, 226, 1))
+	}
+}
+
+func (n *Notification) setContent(l NotifyLevel, title, message string) {
+	level := notifyLevelSvg(l)
+	n.levelIcon.Load2(core.NewQByteArray2(level, len(level)))
+
+	n.title.SetText(title)
+	n.title.SetVisible(title != "")
+	n.label.SetWordWrap(utf8.RuneCountInString(message) > 50)
+	n.label.SetText(message)
+}
+
+// setButtons replaces the button row of the notification with the buttons
+func (n *Notification) setButtons(buttons []*NotifyButton) {
+	if n.buttonRow != nil {
+		n.layout.RemoveWidget(n.buttonRow)
+		n.buttonRow.DeleteLater()
+		n.buttonRow = nil
+	}
+	n.buttons = buttons
+	if len(buttons) == 0 {
+		return
+	}
+
+	bottomwidget := widgets.NewQWidget(nil, 0)
+	bottomlayout := widgets.NewQHBoxLayout()
+	bottomlayout.SetContentsMargins(0, 0, 0, 0)
+	bottomlayout.SetSpacing(10)
+	bottomwidget.SetLayout(bottomlayout)
+	for _, opt := range buttons {
+		if opt.text != "" {
+			// * plugin install button
+			fn := opt.action
+			clicked := opt.clicked
+			button := newNotifyButtonWidget(opt.text, func() {
+				n.closeNotification()
+				if clicked == nil || clicked() {
+					go fn()
+				}
+			})
+			bottomlayout.AddWidget(button, 0, 0)
+			bottomlayout.SetAlignment(button, core.Qt__AlignRight)
+		}
+	}
+	n.layout.SetSpacing(8)
+	bottomwidget.AdjustSize()
+	n.layout.AddWidget(bottomwidget, 0, 0)
+	n.layout.SetAlignment(bottomwidget, core.Qt__AlignRight)
+	n.buttonRow = bottomwidget
+}
+
+// update replaces the content of the shown notification, for example to
+// report the progress of a task. The buttons are kept unless the new content
+// has its own.
+func (n *Notification) update(l NotifyLevel, p int, title, message string, buttons []*NotifyButton) {
+	n.setContent(l, title, message)
+	if len(buttons) > 0 {
+		n.setButtons(buttons)
+	}
+	n.widget.AdjustSize()
+	if n.isHide {
+		n.statusReset()
+	}
+	editor.updateNotificationPos()
+	n.startTimer(p)
+}
+
+// newNotifyButtonWidget returns the button of notifications which calls
+// clicked when it is pressed
+func newNotifyButtonWidget(text string, clicked func()) *widgets.QWidget {
+	buttonLabel := widgets.NewQLabel(nil, 0)
+	buttonLabel.SetFont(gui.NewQFont2(editor.extFontFamily, editor.extFontSize-1, 1, false))
+	buttonLabel.SetFixedHeight(28)
+	buttonLabel.SetContentsMargins(10, 5, 10, 5)
+	buttonLabel.SetAlignment(core.Qt__AlignCenter)
+	button := widgets.NewQWidget(nil, 0)
+	buttonLayout := widgets.NewQHBoxLayout()
+	buttonLayout.SetContentsMargins(0, 0, 0, 0)
+	buttonLayout.AddWidget(buttonLabel, 0, 0)
+	button.SetLayout(buttonLayout)
+	button.SetObjectName("button")
+	buttonLabel.SetText(text)
+	color := "#0e639c"
+	button.SetStyleSheet(fmt.Sprintf(" #button QLabel { color: #ffffff; background: %s;} ", color))
+	button.ConnectMousePressEvent(func(*gui.QMouseEvent) {
+		clicked()
+	})
+	button.ConnectEnterEvent(func(event *core.QEvent) {
+		hoverColor := "#1177bb"
+		button.SetStyleSheet(fmt.Sprintf(" #button QLabel { color: #ffffff; background: %s;} ", hoverColor))
+	})
+	button.ConnectLeaveEvent(func(event *core.QEvent) {
+		button.SetStyleSheet(fmt.Sprintf(" #button QLabel { color: #ffffff; background: %s;} ", color))
+	})
+
+	return button
 }
 
 func (n *Notification) dropNotifications(fn ...func(*Notification)) {
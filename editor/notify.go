@@ -0,0 +1,138 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+// goneovimSetupLua sets up the goneovim Lua module of runtime/lua/goneovim.lua.
+// The module is loaded from its source if nvim can not find it, as the
+// runtime of goneovim is not in the 'runtimepath' of a remote nvim.
+const goneovimSetupLua = `
+local source, override = ...
+local ok, M = pcall(require, "goneovim")
+if not ok then
+  if source == "" then
+    error(M)
+  end
+  M = assert(loadstring(source, "=goneovim.lua"))()
+  package.loaded["goneovim"] = M
+end
+M.setup({override_notify = override})
+`
+
+// pluginNotification is a notification posted by the goneovim Lua module
+type pluginNotification struct {
+	id      int
+	level   NotifyLevel
+	title   string
+	timeout int
+	message string
+	buttons []string
+}
+
+// registerGoneovimLua sets up the goneovim Lua module in nvim
+func (w *Workspace) registerGoneovimLua() {
+	source := ""
+	if w.uiRemoteAttached {
+		data, err := ioutil.ReadFile(filepath.Join(getResourcePath(), "runtime", "lua", "goneovim.lua"))
+		if err != nil {
+			editor.putLog("failed to read the goneovim lua module:", err)
+		}
+		source = string(data)
+	}
+	err := w.nvim.ExecLua(goneovimSetupLua, nil, source, editor.config.Notification.OverrideVimNotify)
+	if err != nil {
+		editor.putLog("failed to set up the goneovim lua module:", err)
+	}
+}
+
+// parseNotifyLevel converts the level name or the value of vim.log.levels
+// into NotifyLevel
+func parseNotifyLevel(level interface{}) NotifyLevel {
+	switch l := level.(type) {
+	case string:
+		switch strings.ToLower(l) {
+		case "error":
+			return NotifyError
+		case "warn", "warning":
+			return NotifyWarn
+		case "debug", "trace":
+			return NotifyDebug
+		}
+	case int64, uint64, int, uint:
+		switch n := util.ReflectToInt(l); {
+		case n >= 4: // vim.log.levels.ERROR
+			return NotifyError
+		case n == 3: // vim.log.levels.WARN
+			return NotifyWarn
+		case n <= 1: // vim.log.levels.TRACE and DEBUG
+			return NotifyDebug
+		}
+	}
+
+	return NotifyInfo
+}
+
+func parsePluginNotification(arg interface{}) *pluginNotification {
+	m, ok := arg.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	n := &pluginNotification{
+		id:      util.ReflectToInt(m["id"]),
+		level:   parseNotifyLevel(m["level"]),
+		timeout: -1,
+	}
+	if m["timeout"] != nil {
+		n.timeout = util.ReflectToInt(m["timeout"])
+	}
+	n.title, _ = m["title"].(string)
+	n.message, _ = m["message"].(string)
+	buttons, _ := m["buttons"].([]interface{})
+	for _, b := range buttons {
+		if text, ok := b.(string); ok {
+			n.buttons = append(n.buttons, text)
+		}
+	}
+
+	return n
+}
+
+// pluginNotify shows the notification posted by the gonvim_notify Gui event.
+// The clicked button is reported back to the goneovim Lua module, which is
+// told as well when the notification is dropped without a click.
+func (w *Workspace) pluginNotify(arg interface{}) {
+	n := parsePluginNotification(arg)
+	if n == nil || n.message == "" {
+		return
+	}
+	var buttons []*NotifyButton
+	for i, text := range n.buttons {
+		index := i + 1
+		text := text
+		buttons = append(buttons, &NotifyButton{
+			action: func() {
+				w.nvim.ExecLua(`require("goneovim")._clicked(...)`, nil, n.id, index, text)
+			},
+			text: text,
+		})
+	}
+
+	editor.pushNotification(
+		n.level,
+		n.timeout,
+		n.message,
+		notifyOptionArg(buttons),
+		// The ids are given by each nvim
+		notifyIDOptionArg(fmt.Sprintf("%p:%d", w, n.id)),
+		notifyTitleOptionArg(n.title),
+		notifyDismissOptionArg(func() {
+			go w.nvim.ExecLua(`require("goneovim")._dismissed(...)`, nil, n.id)
+		}),
+	)
+}
@@ -0,0 +1,88 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNotifyLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level interface{}
+		want  NotifyLevel
+	}{
+		{"error", "error", NotifyError},
+		{"warning", "WARNING", NotifyWarn},
+		{"info", "info", NotifyInfo},
+		{"debug", "debug", NotifyDebug},
+		{"vim.log.levels.ERROR", int64(4), NotifyError},
+		{"vim.log.levels.WARN", int64(3), NotifyWarn},
+		{"vim.log.levels.INFO", int64(2), NotifyInfo},
+		{"vim.log.levels.DEBUG", uint64(1), NotifyDebug},
+		{"vim.log.levels.TRACE", int64(0), NotifyDebug},
+		{"unknown", nil, NotifyInfo},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNotifyLevel(tt.level); got != tt.want {
+				t.Errorf("parseNotifyLevel(%v) = %v, want %v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePluginNotification(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  interface{}
+		want *pluginNotification
+	}{
+		{
+			"with buttons",
+			map[string]interface{}{
+				"id":      int64(3),
+				"level":   "warn",
+				"title":   "foo.txt",
+				"timeout": int64(0),
+				"message": "reload file?",
+				"buttons": []interface{}{"Reload", "Ignore"},
+			},
+			&pluginNotification{
+				id:      3,
+				level:   NotifyWarn,
+				title:   "foo.txt",
+				timeout: 0,
+				message: "reload file?",
+				buttons: []string{"Reload", "Ignore"},
+			},
+		},
+		{
+			"level of vim.log.levels and default timeout",
+			map[string]interface{}{
+				"id":      int64(1),
+				"level":   int64(2),
+				"message": "done",
+			},
+			&pluginNotification{
+				id:      1,
+				level:   NotifyInfo,
+				timeout: -1,
+				message: "done",
+			},
+		},
+		{
+			"not a dictionary",
+			"message",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePluginNotification(tt.arg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePluginNotification() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package editor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/svg"
+	"github.com/therecipe/qt/widgets"
+)
+
+// notifyHistoryLen is the number of notifications kept in the notification center
+const notifyHistoryLen = 200
+
+// notifyLevelNames are the names of the levels used in the config
+var notifyLevelNames = map[string]NotifyLevel{
+	"error": NotifyError,
+	"warn":  NotifyWarn,
+	"info":  NotifyInfo,
+	"debug": NotifyDebug,
+}
+
+// notifyRecord is a notification kept in the notification center
+type notifyRecord struct {
+	id      string
+	level   NotifyLevel
+	title   string
+	message string
+	buttons []*NotifyButton
+	time    time.Time
+	// clicked is set when one of the buttons has been clicked, and the
+	// buttons are no longer offered
+	clicked bool
+	// dismiss is called when the record is dropped from the history
+	dismiss func()
+}
+
+// NotificationCenter lists the past notifications
+type NotificationCenter struct {
+	widget  *widgets.QWidget
+	dnd     *widgets.QPushButton
+	area    *widgets.QScrollArea
+	isShown bool
+}
+
+// appendNotifyHistory adds the record to the history. A record with the id of
+// a kept one replaces it. The oldest records are dropped beyond max records,
+// and dismissed.
+func appendNotifyHistory(history []*notifyRecord, r *notifyRecord, max int) []*notifyRecord {
+	if r.id != "" {
+		for i, h := range history {
+			if h.id != r.id {
+				continue
+			}
+			if r.buttons == nil {
+				r.buttons = h.buttons
+				r.clicked = h.clicked
+				r.dismiss = h.dismiss
+			}
+			history = append(history[:i], history[i+1:]...)
+			break
+		}
+	}
+	history = append(history, r)
+	if over := len(history) - max; over > 0 {
+		dismissNotifyRecords(history[:over])
+		history = history[over:]
+	}
+
+	return history
+}
+
+func dismissNotifyRecords(records []*notifyRecord) {
+	for _, r := range records {
+		if r.dismiss != nil {
+			r.dismiss()
+		}
+	}
+}
+
+// hasNotifyLevel reports whether the level is in the level names of the config
+func hasNotifyLevel(level NotifyLevel, names []string) bool {
+	for _, name := range names {
+		if l, ok := notifyLevelNames[name]; ok && l == level {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordNotification keeps the notification in the history, and reports
+// whether it should pop up
+func (e *Editor) recordNotification(notify *Notify) bool {
+	r := &notifyRecord{
+		id:      notify.id,
+		level:   notify.level,
+		title:   notify.title,
+		message: notify.message,
+		time:    time.Now(),
+		dismiss: notify.dismiss,
+	}
+	// The buttons of the record are disabled once one of them is clicked,
+	// either in the popup or in the notification center
+	for _, b := range notify.buttons {
+		r.buttons = append(r.buttons, &NotifyButton{
+			action:  b.action,
+			text:    b.text,
+			clicked: r.click,
+		})
+	}
+	if len(r.buttons) > 0 {
+		notify.buttons = r.buttons
+	}
+	e.notifyHistory = appendNotifyHistory(e.notifyHistory, r, notifyHistoryLen)
+	if e.notifyCenter != nil {
+		e.notifyCenter.refresh()
+	}
+
+	if e.doNotDisturb {
+		return false
+	}
+
+	return !hasNotifyLevel(notify.level, e.config.Notification.MuteLevels)
+}
+
+// click marks the record clicked, and closes the popups which still offer its
+// buttons. It reports false if one of the buttons has already been clicked.
+func (r *notifyRecord) click() bool {
+	if r.clicked {
+		return false
+	}
+	r.clicked = true
+	for _, n := range append([]*Notification{}, editor.notifications...) {
+		if len(n.buttons) > 0 && len(r.buttons) > 0 && n.buttons[0] == r.buttons[0] {
+			n.closeNotification()
+		}
+	}
+	if editor.notifyCenter != nil {
+		editor.notifyCenter.refresh()
+	}
+
+	return true
+}
+
+func newNotificationCenter() *NotificationCenter {
+	widget := widgets.NewQWidget(editor.window, 0)
+	widget.SetObjectName("notificationcenter")
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(10, 10, 10, 10)
+	layout.SetSpacing(8)
+	widget.SetLayout(layout)
+
+	header := widgets.NewQHBoxLayout()
+	header.SetContentsMargins(0, 0, 0, 0)
+	header.SetSpacing(6)
+	title := widgets.NewQLabel2("NOTIFICATIONS", nil, 0)
+	dnd := widgets.NewQPushButton2("", nil)
+	dnd.SetCheckable(true)
+	clearButton := widgets.NewQPushButton2("Clear", nil)
+	closeButton := widgets.NewQPushButton2("Close", nil)
+	for _, b := range []*widgets.QPushButton{dnd, clearButton, closeButton} {
+		b.SetFocusPolicy(core.Qt__NoFocus)
+	}
+	header.AddWidget(title, 1, 0)
+	header.AddWidget(dnd, 0, 0)
+	header.AddWidget(clearButton, 0, 0)
+	header.AddWidget(closeButton, 0, 0)
+
+	area := widgets.NewQScrollArea(nil)
+	area.SetWidgetResizable(true)
+	area.SetFrameShape(widgets.QFrame__NoFrame)
+	area.SetHorizontalScrollBarPolicy(core.Qt__ScrollBarAlwaysOff)
+	area.SetFocusPolicy(core.Qt__NoFocus)
+
+	layout.AddLayout(header, 0)
+	layout.AddWidget(area, 1, 0)
+
+	c := &NotificationCenter{
+		widget: widget,
+		dnd:    dnd,
+		area:   area,
+	}
+
+	dnd.ConnectClicked(func(bool) {
+		editor.toggleDoNotDisturb()
+	})
+	clearButton.ConnectClicked(func(bool) {
+		dismissNotifyRecords(editor.notifyHistory)
+		editor.notifyHistory = nil
+		c.refresh()
+	})
+	closeButton.ConnectClicked(func(bool) {
+		c.hide()
+	})
+	widget.Hide()
+
+	return c
+}
+
+func (e *Editor) toggleNotificationCenter() {
+	if e.notifyCenter == nil {
+		e.notifyCenter = newNotificationCenter()
+	}
+	if e.notifyCenter.isShown {
+		e.notifyCenter.hide()
+		return
+	}
+	e.notifyCenter.show()
+}
+
+// toggleDoNotDisturb suppresses the popups of notifications, which are still
+// kept in the notification center
+func (e *Editor) toggleDoNotDisturb() {
+	e.doNotDisturb = !e.doNotDisturb
+	if e.doNotDisturb {
+		e.hideNotifications()
+	}
+	if e.notifyCenter != nil {
+		e.notifyCenter.refresh()
+	}
+}
+
+func (c *NotificationCenter) show() {
+	c.isShown = true
+	e := editor
+	width := e.notificationWidth
+	c.widget.SetFixedSize2(width, e.window.Height()-40)
+	c.widget.Move2(e.window.Width()-width-10, 10)
+	bg := e.colors.widgetBg
+	c.widget.SetStyleSheet(fmt.Sprintf(`
+		#notificationcenter, #notificationcenter QWidget { color: %[1]s; background: rgba(%[2]d, %[3]d, %[4]d, %[5]f); }
+		#notificationcenter QPushButton { border: 0px; padding: 3px 8px; background: %[6]s; }
+		#notificationcenter QPushButton:checked { background: %[7]s; }
+		`,
+		e.colors.widgetFg.String(),
+		bg.R, bg.G, bg.B, transparent(),
+		e.colors.widgetInputArea.String(),
+		e.colors.selectedBg.String(),
+	))
+	c.refresh()
+	c.widget.Show()
+	c.widget.Raise()
+}
+
+func (c *NotificationCenter) hide() {
+	c.isShown = false
+	c.widget.Hide()
+}
+
+func (c *NotificationCenter) refresh() {
+	if editor.doNotDisturb {
+		c.dnd.SetText("Do Not Disturb: On")
+	} else {
+		c.dnd.SetText("Do Not Disturb: Off")
+	}
+	c.dnd.SetChecked(editor.doNotDisturb)
+	if !c.isShown {
+		return
+	}
+
+	entries := widgets.NewQWidget(nil, 0)
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(12)
+	entries.SetLayout(layout)
+	history := editor.notifyHistory
+	for i := len(history) - 1; i >= 0; i-- {
+		layout.AddWidget(newNotifyRecordWidget(history[i], c), 0, 0)
+	}
+	if len(history) == 0 {
+		empty := widgets.NewQLabel2("No notifications", nil, 0)
+		empty.SetStyleSheet(fmt.Sprintf(" * { color: %s; }", editor.colors.inactiveFg.String()))
+		layout.AddWidget(empty, 0, core.Qt__AlignCenter)
+	}
+	layout.AddStretch(1)
+
+	// The entries may be replaced from the click on one of their buttons, so
+	// that the previous ones are deleted later
+	if old := c.area.TakeWidget(); old != nil && old.Pointer() != nil {
+		old.DeleteLater()
+	}
+	c.area.SetWidget(entries)
+}
+
+func newNotifyRecordWidget(r *notifyRecord, c *NotificationCenter) *widgets.QWidget {
+	widget := widgets.NewQWidget(nil, 0)
+	layout := widgets.NewQHBoxLayout()
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(8)
+	widget.SetLayout(layout)
+
+	levelIcon := svg.NewQSvgWidget(nil)
+	levelIcon.SetFixedSize2(editor.iconSize, editor.iconSize)
+	level := notifyLevelSvg(r.level)
+	levelIcon.Load2(core.NewQByteArray2(level, len(level)))
+
+	textLayout := widgets.NewQVBoxLayout()
+	textLayout.SetContentsMargins(0, 0, 0, 0)
+	textLayout.SetSpacing(4)
+
+	header := r.time.Format("15:04:05")
+	if r.title != "" {
+		header = r.title + "  " + header
+	}
+	title := widgets.NewQLabel2(header, nil, 0)
+	title.SetFont(gui.NewQFont2(editor.extFontFamily, editor.extFontSize-1, int(gui.QFont__Bold), false))
+	message := widgets.NewQLabel2(r.message, nil, 0)
+	message.SetWordWrap(true)
+	message.SetTextInteractionFlags(core.Qt__TextSelectableByMouse)
+	message.SetFont(gui.NewQFont2(editor.extFontFamily, editor.extFontSize, 1, false))
+	textLayout.AddWidget(title, 0, 0)
+	textLayout.AddWidget(message, 0, 0)
+
+	if len(r.buttons) > 0 && !r.clicked {
+		buttons := widgets.NewQHBoxLayout()
+		buttons.SetContentsMargins(0, 0, 0, 0)
+		buttons.SetSpacing(10)
+		buttons.AddStretch(1)
+		for _, b := range r.buttons {
+			action := b.action
+			buttons.AddWidget(newNotifyButtonWidget(b.text, func() {
+				if !r.click() {
+					return
+				}
+				go action()
+			}), 0, 0)
+		}
+		textLayout.AddLayout(buttons, 0)
+	}
+
+	layout.AddWidget(levelIcon, 0, core.Qt__AlignTop)
+	layout.AddLayout(textLayout, 1)
+
+	return widget
+}
@@ -0,0 +1,96 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendNotifyHistory(t *testing.T) {
+	button := &NotifyButton{text: "Reload"}
+	a := &notifyRecord{message: "a"}
+	b := &notifyRecord{id: "b", message: "b", buttons: []*NotifyButton{button}, clicked: true}
+	c := &notifyRecord{message: "c"}
+
+	tests := []struct {
+		name    string
+		history []*notifyRecord
+		record  *notifyRecord
+		max     int
+		want    []*notifyRecord
+	}{
+		{
+			"append",
+			[]*notifyRecord{a},
+			c,
+			10,
+			[]*notifyRecord{a, c},
+		},
+		{
+			"drop the oldest",
+			[]*notifyRecord{a, b},
+			c,
+			2,
+			[]*notifyRecord{b, c},
+		},
+		{
+			"replace by id keeping the buttons",
+			[]*notifyRecord{a, b, c},
+			&notifyRecord{id: "b", message: "b 50%"},
+			10,
+			[]*notifyRecord{a, c, {id: "b", message: "b 50%", buttons: []*NotifyButton{button}, clicked: true}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			history := append([]*notifyRecord{}, tt.history...)
+			if got := appendNotifyHistory(history, tt.record, tt.max); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendNotifyHistory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendNotifyHistoryDismiss(t *testing.T) {
+	var dismissed []string
+	record := func(id string) *notifyRecord {
+		return &notifyRecord{id: id, dismiss: func() {
+			dismissed = append(dismissed, id)
+		}}
+	}
+	history := []*notifyRecord{record("a"), record("b")}
+	history = appendNotifyHistory(history, &notifyRecord{id: "b"}, 2)
+	if len(dismissed) != 0 {
+		t.Fatalf("replaced records are dismissed: %v", dismissed)
+	}
+	history = appendNotifyHistory(history, record("c"), 2)
+	if !reflect.DeepEqual(dismissed, []string{"a"}) {
+		t.Errorf("dismissed = %v, want [a]", dismissed)
+	}
+	history[0].dismiss()
+	if !reflect.DeepEqual(dismissed, []string{"a", "b"}) {
+		t.Errorf("the replacement of b does not keep its dismiss: dismissed = %v", dismissed)
+	}
+}
+
+func TestHasNotifyLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level NotifyLevel
+		names []string
+		want  bool
+	}{
+		{"included", NotifyDebug, []string{"debug"}, true},
+		{"not included", NotifyError, []string{"debug", "info"}, false},
+		{"unknown names are ignored", NotifyInfo, []string{"verbose"}, false},
+		{"no names", NotifyWarn, nil, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasNotifyLevel(tt.level, tt.names); got != tt.want {
+				t.Errorf("hasNotifyLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package editor
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// startTestNvim starts an embedded nvim without any config, for the tests of
+// the lua run in the nvim of workspaces. The test is skipped if nvim is not
+// installed.
+func startTestNvim(tb testing.TB) *nvim.Nvim {
+	path, err := exec.LookPath("nvim")
+	if err != nil {
+		tb.Skip("nvim is not installed")
+	}
+	neovim, err := nvim.NewChildProcess(
+		nvim.ChildProcessCommand(path),
+		nvim.ChildProcessArgs("-u", "NONE", "-i", "NONE", "-n", "--headless", "--embed"),
+	)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		neovim.Close()
+	})
+
+	return neovim
+}
+
+// setTestBuffer replaces the lines of the current buffer
+func setTestBuffer(tb testing.TB, neovim *nvim.Nvim, lines []string) {
+	replacement := make([][]byte, len(lines))
+	for i, line := range lines {
+		replacement[i] = []byte(line)
+	}
+	if err := neovim.SetBufferLines(0, 0, -1, true, replacement); err != nil {
+		tb.Fatal(err)
+	}
+}
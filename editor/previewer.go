@@ -0,0 +1,163 @@
+package editor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// previewRenderer converts the contents of the buffer of path to the HTML shown in the preview
+type previewRenderer func(content []byte, path string) (string, error)
+
+var previewImageExts = []string{".png", ".jpg", ".jpeg", ".gif", ".bmp", ".webp", ".ico"}
+
+var (
+	htmlBodyRegexp   = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+	htmlStyleRegexp  = regexp.MustCompile(`(?is)<style[^>]*>.*?</style>|<link\s[^>]*rel="?stylesheet"?[^>]*>`)
+	htmlPrologRegexp = regexp.MustCompile(`(?is)^\s*(<\?xml.*?\?>\s*)?(<!DOCTYPE[^>]*>\s*)?`)
+)
+
+// getPreviewRenderer returns the renderer for the buffer of filetype and path.
+// Renderers configured by the user take precedence over the built-in ones.
+func getPreviewRenderer(filetype, path string) (previewRenderer, bool) {
+	if command, ok := editor.config.Markdown.Previewers[filetype]; ok && command != "" {
+		return newExternalRenderer(command), true
+	}
+	switch filetype {
+	case "markdown":
+		return renderMarkdown, true
+	case "html":
+		return renderHTML, true
+	case "svg":
+		return renderSVG, true
+	}
+	if isPreviewImage(path) {
+		return renderImage, true
+	}
+
+	return nil, false
+}
+
+// isPreviewImage reports whether path is an image that can be shown in the preview
+func isPreviewImage(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range previewImageExts {
+		if ext == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// previewFiletypes returns the filetypes which have a renderer
+func previewFiletypes() []string {
+	filetypes := []string{"markdown", "html", "svg"}
+	for filetype, command := range editor.config.Markdown.Previewers {
+		if command == "" || filetype == "markdown" || filetype == "html" || filetype == "svg" {
+			continue
+		}
+		filetypes = append(filetypes, filetype)
+	}
+	sort.Strings(filetypes[3:])
+
+	return filetypes
+}
+
+// previewAutoCmdPatterns returns the filetype list and file patterns used by
+// the autocmds which keep the preview up to date.
+func previewAutoCmdPatterns() (string, string) {
+	var filetypes []string
+	for _, filetype := range previewFiletypes() {
+		// The autocmds are registered as single quoted strings
+		filetypes = append(filetypes, strconv.Quote(strings.Replace(filetype, `'`, "", -1)))
+	}
+	var patterns []string
+	for _, ext := range previewImageExts {
+		patterns = append(patterns, "*"+ext)
+	}
+
+	return "[" + strings.Join(filetypes, ", ") + "]", strings.Join(patterns, ",")
+}
+
+func renderMarkdown(content []byte, path string) (string, error) {
+	if editor.config.Markdown.HideFrontMatter {
+		content = blankFrontMatter(content)
+	}
+	var buff bytes.Buffer
+	if err := newMarkdownConverter().Convert(content, &buff); err != nil {
+		return "", err
+	}
+
+	return buff.String(), nil
+}
+
+// renderHTML shows the body of an HTML document, along with its stylesheets
+func renderHTML(content []byte, path string) (string, error) {
+	document := htmlPrologRegexp.ReplaceAllString(string(content), "")
+	match := htmlBodyRegexp.FindStringSubmatch(document)
+	if match == nil {
+		return document, nil
+	}
+	styles := htmlStyleRegexp.FindAllString(document[:strings.Index(document, match[0])], -1)
+
+	return strings.Join(styles, "\n") + match[1], nil
+}
+
+func renderSVG(content []byte, path string) (string, error) {
+	return fmt.Sprintf(
+		`<div class="gonvim-preview-image">%s</div>`,
+		htmlPrologRegexp.ReplaceAllString(string(content), ""),
+	), nil
+}
+
+// renderImage refers to the image relative to its directory,
+// so that it is reloaded when the file is changed.
+func renderImage(content []byte, path string) (string, error) {
+	return fmt.Sprintf(
+		`<div class="gonvim-preview-image"><img src="%s"></div>`,
+		url.PathEscape(filepath.Base(path)),
+	), nil
+}
+
+// newExternalRenderer returns a renderer which passes the buffer to command on
+// stdin and shows its output, which is either HTML or SVG.
+func newExternalRenderer(command string) previewRenderer {
+	return func(content []byte, path string) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		if path != "" {
+			cmd.Dir = filepath.Dir(path)
+		}
+		cmd.Stdin = bytes.NewReader(content)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+		}
+
+		document := htmlPrologRegexp.ReplaceAllString(string(output), "")
+		if strings.HasPrefix(document, "<svg") {
+			return renderSVG(output, path)
+		}
+
+		return renderHTML(output, path)
+	}
+}
@@ -0,0 +1,41 @@
+package editor
+
+import (
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"renderHTML() fragment is kept",
+			"<p>a</p>\n",
+			"<p>a</p>\n",
+		},
+		{
+			"renderHTML() body and styles of a document are shown",
+			"<!DOCTYPE html>\n<html><head><title>t</title><style>p { color: red; }</style></head><body class=\"b\"><p>a</p></body></html>",
+			"<style>p { color: red; }</style><p>a</p>",
+		},
+		{
+			"renderHTML() xml prolog is removed",
+			"<?xml version=\"1.0\"?>\n<!DOCTYPE html>\n<html><body><p>a</p></body></html>",
+			"<p>a</p>",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderHTML([]byte(tt.content), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("renderHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -141,6 +141,8 @@ type Window struct {
 	width        float64
 	height       int
 	localWindows *[4]localWindow
+
+	scrollBar *WindowScrollBar
 }
 
 type localWindow struct {
@@ -1204,6 +1206,9 @@ func (s *Screen) bottomWindowPos() int {
 }
 
 func (w *Window) wheelEvent(event *gui.QWheelEvent) {
+	if !w.s.ws.isMouseEnabled {
+		return
+	}
 	var v, h, vert, horiz int
 	var vertKey string
 	var horizKey string
@@ -1469,6 +1474,16 @@ func (s *Screen) mousePressEvent(event *gui.QMouseEvent) {
 }
 
 func (s *Screen) mouseEvent(event *gui.QMouseEvent) {
+	// Respect 'mouse' as terminals do
+	if !s.ws.isMouseEnabled {
+		return
+	}
+	if s.ws.menu.hasContextMenu() && (event.Button() == core.Qt__RightButton || event.Buttons()&core.Qt__RightButton > 0) {
+		if event.Type() == core.QEvent__MouseButtonPress {
+			s.ws.menu.requestContextMenu(event)
+		}
+		return
+	}
 	inp := s.convertMouse(event)
 	if inp == "" {
 		return
@@ -1476,10 +1491,17 @@ func (s *Screen) mouseEvent(event *gui.QMouseEvent) {
 	s.ws.nvim.Input(inp)
 }
 
-func (s *Screen) convertMouse(event *gui.QMouseEvent) string {
+// mouseCell returns the cell of the grid under the mouse
+func (s *Screen) mouseCell(event *gui.QMouseEvent) (int, int) {
 	font := s.font
 	x := int(float64(event.X()) / font.truewidth)
 	y := int(float64(event.Y()) / float64(font.lineHeight))
+
+	return x, y
+}
+
+func (s *Screen) convertMouse(event *gui.QMouseEvent) string {
+	x, y := s.mouseCell(event)
 	pos := []int{x, y}
 
 	bt := event.Button()
@@ -2025,6 +2047,82 @@ func (hl *Highlight) bg() *RGBA {
 	return color
 }
 
+// toHTML returns text, which must already be escaped, styled with the
+// attributes of hl in the same way as the grid draws them. The background is
+// left out when it is defaultBg, so that the widget background shows through.
+func (hl *Highlight) toHTML(text string, defaultBg *RGBA) string {
+	styles := []string{"color: " + hl.fg().Hex()}
+	if hl.reverse || hl.background != nil {
+		if bg := hl.bg(); !bg.equals(defaultBg) {
+			styles = append(styles, "background-color: "+bg.Hex())
+		}
+	}
+	if hl.bold {
+		styles = append(styles, "font-weight: bold")
+	}
+	if hl.italic {
+		styles = append(styles, "font-style: italic")
+	}
+	var decorations []string
+	if hl.underline || hl.undercurl {
+		decorations = append(decorations, "underline")
+	}
+	if hl.strikethrough {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		styles = append(styles, "text-decoration: "+strings.Join(decorations, " "))
+	}
+	if hl.undercurl {
+		styles = append(styles, "text-underline-style: wave")
+	}
+
+	return fmt.Sprintf(`<span style="%s">%s</span>`, strings.Join(styles, "; "), text)
+}
+
+// attrHighlight returns the highlight of attr_id, or the default one
+func (s *Screen) attrHighlight(id int) *Highlight {
+	if hl, ok := s.hlAttrDef[id]; ok && hl != nil {
+		return hl
+	}
+
+	return &Highlight{
+		foreground: s.ws.foreground,
+		background: s.ws.background,
+	}
+}
+
+// chunksHTML returns the HTML of the [attr_id, text] chunks sent by the
+// ext_cmdline and ext_messages events
+func (s *Screen) chunksHTML(chunks []interface{}) string {
+	html := ""
+	for _, e := range chunks {
+		a, ok := e.([]interface{})
+		if !ok || len(a) < 2 {
+			continue
+		}
+		text, _ := a[1].(string)
+		html += s.attrHighlight(util.ReflectToInt(a[0])).toHTML(sanitize(text), s.ws.background)
+	}
+
+	return html
+}
+
+// chunksText returns the text of the [attr_id, text] chunks
+func chunksText(chunks []interface{}) string {
+	text := ""
+	for _, e := range chunks {
+		a, ok := e.([]interface{})
+		if !ok || len(a) < 2 {
+			continue
+		}
+		s, _ := a[1].(string)
+		text += s
+	}
+
+	return text
+}
+
 func (s *Screen) gridClear(args []interface{}) {
 	var gridid gridId
 	for _, arg := range args {
@@ -96,6 +96,46 @@ func TestHighlight_fg(t *testing.T) {
 	}
 }
 
+func TestHighlight_toHTML(t *testing.T) {
+	fg := &RGBA{R: 10, G: 20, B: 30, A: 1.0}
+	bg := &RGBA{R: 200, G: 210, B: 220, A: 1.0}
+	defaultBg := &RGBA{R: 0, G: 0, B: 0, A: 1.0}
+	tests := []struct {
+		name string
+		hl   *Highlight
+		want string
+	}{
+		{
+			"test_highlight_toHTML() foreground only",
+			&Highlight{foreground: fg},
+			`<span style="color: #0a141e">a</span>`,
+		},
+		{
+			"test_highlight_toHTML() default background is left out",
+			&Highlight{foreground: fg, background: defaultBg},
+			`<span style="color: #0a141e">a</span>`,
+		},
+		{
+			"test_highlight_toHTML() reverse",
+			&Highlight{foreground: fg, background: bg, reverse: true},
+			`<span style="color: #c8d2dc; background-color: #0a141e">a</span>`,
+		},
+		{
+			"test_highlight_toHTML() attributes",
+			&Highlight{foreground: fg, bold: true, italic: true, undercurl: true, strikethrough: true},
+			`<span style="color: #0a141e; font-weight: bold; font-style: italic; text-decoration: underline line-through; text-underline-style: wave">a</span>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hl.toHTML("a", defaultBg); got != tt.want {
+				t.Errorf("toHTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestWindow_updateLine(t *testing.T) {
 	type fields struct {
 		//	rwMutex          sync.RWMutex
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/akiyosi/goneovim/util"
 	"github.com/therecipe/qt/core"
@@ -11,6 +12,21 @@ import (
 	"github.com/therecipe/qt/widgets"
 )
 
+const (
+	// scrollBarMarksDelay is the time the scrollbar waits for editing to
+	// pause before its marks are fetched again
+	scrollBarMarksDelay = 200 * time.Millisecond
+
+	// scrollBarSearchBudget is the time in milliseconds nvim may spend on
+	// finding the search matches of the whole buffer
+	scrollBarSearchBudget = 30
+)
+
+type scrollBarSignal struct {
+	core.QObject
+	_ func() `signal:"marksSignal"`
+}
+
 // ScrollBar is
 type ScrollBar struct {
 	mu sync.Mutex
@@ -22,6 +38,15 @@ type ScrollBar struct {
 	height    int
 	isPressed bool
 	beginPosY int
+
+	signal         *scrollBarSignal
+	marksUpdates   chan *bufferMarkers
+	marks          *bufferMarkers
+	cursorLine     int
+	isProcessMarks bool
+	isMarksPending bool
+	isRefreshGit   bool
+	marksTimer     *time.Timer
 }
 
 func newScrollBar() *ScrollBar {
@@ -32,10 +57,19 @@ func newScrollBar() *ScrollBar {
 	thumb.SetFixedWidth(8)
 
 	scrollBar := &ScrollBar{
-		widget: widget,
-		thumb:  thumb,
+		widget:       widget,
+		thumb:        thumb,
+		signal:       NewScrollBarSignal(nil),
+		marksUpdates: make(chan *bufferMarkers, 10),
 	}
 
+	scrollBar.signal.ConnectMarksSignal(func() {
+		scrollBar.marks = <-scrollBar.marksUpdates
+		scrollBar.widget.Update()
+	})
+	scrollBar.widget.ConnectPaintEvent(scrollBar.paint)
+	scrollBar.widget.ConnectMousePressEvent(scrollBar.trackPress)
+
 	scrollBar.thumb.ConnectMousePressEvent(scrollBar.thumbPress)
 	scrollBar.thumb.ConnectMouseMoveEvent(scrollBar.thumbScroll)
 	scrollBar.thumb.ConnectMouseReleaseEvent(scrollBar.thumbRelease)
@@ -185,7 +219,141 @@ func (s *ScrollBar) update() {
 		s.pos = int(float64(top) / float64(s.ws.maxLine) * float64(s.ws.screen.widget.Height()))
 		s.thumb.Move2(0, s.pos)
 		s.widget.Show()
+		if editor.config.ScrollBar.CursorLine && s.cursorLine != s.ws.viewport[2] {
+			s.cursorLine = s.ws.viewport[2]
+			s.widget.Update()
+		}
 	} else {
 		s.widget.Hide()
 	}
 }
+
+// requestMarks updates the marks once the buffer has not been changed for
+// scrollBarMarksDelay. If refreshGit is true, git hunks are compared with HEAD
+// read again.
+func (s *ScrollBar) requestMarks(refreshGit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.isRefreshGit = s.isRefreshGit || refreshGit
+	if s.marksTimer != nil {
+		s.marksTimer.Stop()
+	}
+	s.marksTimer = time.AfterFunc(scrollBarMarksDelay, s.updateMarks)
+}
+
+// updateMarks fetches the marks drawn along the track from the current buffer
+func (s *ScrollBar) updateMarks() {
+	s.mu.Lock()
+	if s.isProcessMarks {
+		s.isMarksPending = true
+		s.mu.Unlock()
+		return
+	}
+	s.isProcessMarks = true
+	refreshGit := s.isRefreshGit
+	s.isRefreshGit = false
+	s.mu.Unlock()
+
+	kinds := bufferMarkerKinds{
+		Diagnostics: editor.config.ScrollBar.Diagnostics,
+		Search:      editor.config.ScrollBar.SearchMatches,
+		Git:         editor.config.ScrollBar.GitChanges,
+		RefreshGit:  refreshGit,
+		// Search matches are left out past the budget in huge buffers
+		SearchBudget: scrollBarSearchBudget,
+	}
+	var marks *bufferMarkers
+	var err error
+	if kinds.Diagnostics || kinds.Search || kinds.Git {
+		// Fetch the marks of the whole buffer
+		marks, err = fetchBufferMarkers(s.ws.nvim, 1, math.MaxInt32, kinds)
+	} else {
+		marks = &bufferMarkers{}
+	}
+
+	s.mu.Lock()
+	s.isProcessMarks = false
+	isMarksPending := s.isMarksPending
+	s.isMarksPending = false
+	s.mu.Unlock()
+
+	if err == nil {
+		s.marksUpdates <- marks
+		s.signal.MarksSignal()
+	}
+	if isMarksPending {
+		s.updateMarks()
+	}
+}
+
+// markPos returns the position of line on the track
+func (s *ScrollBar) markPos(line int) int {
+	if s.ws.maxLine == 0 {
+		return 0
+	}
+
+	return int(float64(line-1) / float64(s.ws.maxLine) * float64(s.widget.Height()))
+}
+
+// paint draws the marks along the track. Git hunks are drawn on the left,
+// search matches in the middle and diagnostics on the right side.
+func (s *ScrollBar) paint(event *gui.QPaintEvent) {
+	p := gui.NewQPainter2(s.widget)
+	defer p.DestroyQPainter()
+
+	width := s.widget.Width()
+	if s.marks != nil {
+		diagnostics := make(map[int]bufferMarker)
+		for _, mark := range s.marks.Markers {
+			y := s.markPos(mark.Line)
+			color := s.marks.markerColor(mark.Kind).QColor()
+			switch mark.Kind {
+			case "add", "change", "delete":
+				p.FillRect5(0, y, 2, 2, color)
+			case "search":
+				p.FillRect5(3, y, width-6, 2, color)
+			default:
+				// Draw the most severe diagnostic at each position
+				if d, ok := diagnostics[y]; !ok || markerPriority(mark.Kind) > markerPriority(d.Kind) {
+					diagnostics[y] = mark
+				}
+			}
+		}
+		for y, mark := range diagnostics {
+			p.FillRect5(width-3, y, 3, 2, s.marks.markerColor(mark.Kind).QColor())
+		}
+	}
+	if editor.config.ScrollBar.CursorLine && s.cursorLine > 0 {
+		color := editor.colors.fg
+		if s.marks != nil {
+			color = s.marks.markerColor("cursor")
+		}
+		p.FillRect5(0, s.markPos(s.cursorLine), width, 1, color.QColor())
+	}
+}
+
+// trackPress jumps to the clicked position of the track, or to a mark close to it
+func (s *ScrollBar) trackPress(e *gui.QMouseEvent) {
+	if e.Button() != core.Qt__LeftButton || s.ws.maxLine == 0 {
+		return
+	}
+	y := e.Y()
+	line := int(float64(y)/float64(s.widget.Height())*float64(s.ws.maxLine)) + 1
+	if line > s.ws.maxLine {
+		line = s.ws.maxLine
+	}
+	col := 1
+	if s.marks != nil {
+		nearest := 3
+		for _, mark := range s.marks.Markers {
+			d := int(math.Abs(float64(s.markPos(mark.Line) - y)))
+			if d < nearest {
+				nearest = d
+				line = mark.Line
+				col = mark.Col + 1
+			}
+		}
+	}
+
+	go s.ws.nvim.Command(fmt.Sprintf("call cursor(%d, %d)", line, col))
+}
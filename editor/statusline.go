@@ -39,6 +39,11 @@ type Statusline struct {
 	fileFormat *StatuslineFileFormat
 	lint       *StatuslineLint
 
+	modeMessage *StatuslineMessage
+	showcmd     *StatuslineMessage
+	ruler       *StatuslineMessage
+	busy        *StatuslineBusy
+
 	updates chan []interface{}
 }
 
@@ -139,6 +144,20 @@ type StatuslineFileFormat struct {
 	c          *StatuslineComponent
 }
 
+// StatuslineMessage shows the text of msg_showmode, msg_showcmd or msg_ruler
+type StatuslineMessage struct {
+	text string
+	c    *StatuslineComponent
+}
+
+// StatuslineBusy shows a spinner while neovim is busy
+type StatuslineBusy struct {
+	isBusy bool
+	frame  int
+	timer  *core.QTimer
+	c      *StatuslineComponent
+}
+
 func initStatusline() *Statusline {
 	widget := widgets.NewQWidget(nil, 0)
 	widget.SetContentsMargins(0, 0, 0, 0)
@@ -285,6 +304,11 @@ func initStatusline() *Statusline {
 	s.filetype = filetype
 	s.filetype.c.hide()
 
+	s.modeMessage = newStatuslineMessage()
+	s.showcmd = newStatuslineMessage()
+	s.ruler = newStatuslineMessage()
+	s.busy = newStatuslineBusy()
+
 	okIcon := svg.NewQSvgWidget(nil)
 	okIcon.SetFixedSize2(editor.iconSize, editor.iconSize)
 	okLabel := widgets.NewQLabel(nil, 0)
@@ -382,6 +406,14 @@ func (s *Statusline) setWidget() {
 			s.widget.Layout().AddWidget(s.lint.c.widget)
 			s.lint.c.isInclude = true
 			s.lint.c.show()
+		case "mode-message", "showcmd", "ruler":
+			c := s.message(rightItem).c
+			s.widget.Layout().AddWidget(c.label)
+			c.isInclude = true
+			c.show()
+		case "busy":
+			s.widget.Layout().AddWidget(s.busy.c.label)
+			s.busy.c.isInclude = true
 		default:
 		}
 	}
@@ -428,6 +460,14 @@ func (left *LeftStatusItem) setWidget() {
 			left.widget.Layout().AddWidget(left.s.lint.c.widget)
 			left.s.lint.c.isInclude = true
 			left.s.lint.c.show()
+		case "mode-message", "showcmd", "ruler":
+			c := left.s.message(leftItem).c
+			left.widget.Layout().AddWidget(c.label)
+			c.isInclude = true
+			c.show()
+		case "busy":
+			left.widget.Layout().AddWidget(left.s.busy.c.label)
+			left.s.busy.c.isInclude = true
 		default:
 		}
 	}
@@ -441,6 +481,10 @@ func (s *Statusline) setContentsMarginsForWidgets(l int, u int, r int, d int) {
 	s.fileFormat.c.label.SetContentsMargins(l, u, r, d)
 	s.encoding.c.label.SetContentsMargins(l, u, r, d)
 	s.lint.c.widget.SetContentsMargins(l, u, r, d)
+	s.modeMessage.c.label.SetContentsMargins(l, u, r, d)
+	s.showcmd.c.label.SetContentsMargins(l, u, r, d)
+	s.ruler.c.label.SetContentsMargins(l, u, r, d)
+	s.busy.c.label.SetContentsMargins(l, u, r, d)
 }
 
 func (s *Statusline) getColor() {
@@ -505,6 +549,10 @@ func (s *Statusline) setColor() {
 	s.encoding.c.setColor(fg, bg)
 	s.fileFormat.c.setColor(fg, bg)
 	s.pos.c.setColor(fg, bg)
+	s.modeMessage.c.setColor(fg, bg)
+	s.showcmd.c.setColor(fg, bg)
+	s.ruler.c.setColor(fg, bg)
+	s.busy.c.setColor(fg, bg)
 
 	s.lint.c.fg = fg
 	s.lint.c.bg = bg
@@ -551,6 +599,10 @@ func (s *Statusline) updateFont() {
 	s.git.c.label.SetFont(font)
 	s.encoding.c.label.SetFont(font)
 	s.fileFormat.c.label.SetFont(font)
+	s.modeMessage.c.label.SetFont(font)
+	s.showcmd.c.label.SetFont(font)
+	s.ruler.c.label.SetFont(font)
+	s.busy.c.label.SetFont(font)
 }
 
 func (s *Statusline) subscribe() {
@@ -897,6 +949,107 @@ func (s *StatuslineFileFormat) redraw(fileFormat string) {
 	s.c.show()
 }
 
+func newStatuslineMessage() *StatuslineMessage {
+	label := widgets.NewQLabel(nil, 0)
+	label.SetTextFormat(core.Qt__RichText)
+	m := &StatuslineMessage{
+		c: &StatuslineComponent{
+			label: label,
+		},
+	}
+	m.c.hide()
+
+	return m
+}
+
+// message returns the component of msg_showmode, msg_showcmd or msg_ruler
+func (s *Statusline) message(component string) *StatuslineMessage {
+	switch component {
+	case "mode-message":
+		return s.modeMessage
+	case "showcmd":
+		return s.showcmd
+	default:
+		return s.ruler
+	}
+}
+
+// setMessage shows the HTML text in the component, and reports whether the
+// component is in the statusline
+func (s *Statusline) setMessage(component string, text string) bool {
+	if !s.ws.drawStatusline {
+		return false
+	}
+	m := s.message(component)
+	if !m.c.isInclude {
+		return false
+	}
+	m.redraw(text)
+
+	return true
+}
+
+func (s *StatuslineMessage) redraw(text string) {
+	if s.text == text {
+		return
+	}
+	s.text = text
+	s.c.label.SetText(text)
+	if text == "" {
+		s.c.hide()
+		return
+	}
+	s.c.show()
+}
+
+// busySpinnerFrames are the frames of the busy spinner
+var busySpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// busySpinnerDelay is the time in milliseconds neovim has to be busy
+	// before the spinner is shown, so that short jobs don't flicker it
+	busySpinnerDelay    = 300
+	busySpinnerInterval = 80
+)
+
+func newStatuslineBusy() *StatuslineBusy {
+	b := &StatuslineBusy{
+		c: &StatuslineComponent{
+			label: widgets.NewQLabel(nil, 0),
+		},
+		timer: core.NewQTimer(nil),
+	}
+	b.timer.ConnectTimeout(b.spin)
+	b.c.hide()
+
+	return b
+}
+
+// setBusy starts the spinner after busySpinnerDelay, or stops it
+func (s *StatuslineBusy) setBusy(busy bool) {
+	if !s.c.isInclude || s.isBusy == busy {
+		return
+	}
+	s.isBusy = busy
+	if !busy {
+		s.timer.Stop()
+		s.c.hide()
+		return
+	}
+	s.frame = 0
+	s.timer.Start(busySpinnerDelay)
+}
+
+func (s *StatuslineBusy) spin() {
+	if !s.isBusy {
+		return
+	}
+	s.c.label.SetText(busySpinnerFrames[s.frame%len(busySpinnerFrames)])
+	s.frame++
+	s.c.show()
+	s.timer.SetInterval(busySpinnerInterval)
+}
+
 func (s *StatuslineFiletype) redraw(filetype string) {
 	if filetype == s.filetype {
 		return
@@ -635,6 +635,12 @@ func (e *Editor) initSVGS() {
 		xml:    `<svg width="24" height="24" viewBox="0 0 24 24"><path fill="%s" d="M7.41,8.58L12,13.17L16.59,8.58L18,10L12,16L6,10L7.41,8.58Z" /></svg>`,
 	}
 
+	e.svgs["chevron-left"] = &SvgXML{
+		width:  24,
+		height: 24,
+		xml:    `<svg width="24" height="24" viewBox="0 0 24 24"><path fill="%s" d="M15.41,16.58L10.83,12L15.41,7.41L14,6L8,12L14,18L15.41,16.58Z" /></svg>`,
+	}
+
 	e.svgs["chevron-right"] = &SvgXML{
 		width:  24,
 		height: 24,
@@ -2,7 +2,9 @@ package editor
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -15,6 +17,13 @@ import (
 	"github.com/therecipe/qt/widgets"
 )
 
+const (
+	tablineSpacing = 16
+	tablinePadding = 5
+	// tablineScrollStep is the width in pixels scrolled by the overflow buttons
+	tablineScrollStep = 120
+)
+
 // Tabline of the editor
 type Tabline struct {
 	ws              *Workspace
@@ -33,6 +42,34 @@ type Tabline struct {
 	fontsize   int
 
 	color *RGBA
+
+	// viewport clips tabsWidget, which is moved to scroll the tabs
+	viewport       *widgets.QWidget
+	tabsWidget     *widgets.QWidget
+	scrollLeft     *svg.QSvgWidget
+	scrollRight    *svg.QSvgWidget
+	overflowButton *svg.QSvgWidget
+	offset         int
+	tabsWidth      int
+	scrolledToID   int
+
+	// thumbnails are the images of the tabpages when they were last current
+	thumbnails map[int]*gui.QPixmap
+
+	// bufferMode lists the listed buffers instead of tabpages
+	bufferMode bool
+	buffers    []*tablineBuffer
+	// hasBufferList is set when tabline_update carries the buffer list (nvim 0.5+),
+	// otherwise the list is taken from the gonvim_buffers autocmd events
+	hasBufferList bool
+}
+
+// tablineBuffer is a listed buffer shown in the buffer-line mode
+type tablineBuffer struct {
+	id       int
+	name     string
+	modified bool
+	readonly bool
 }
 
 // Tab in the tabline
@@ -49,8 +86,46 @@ type Tab struct {
 	file      *widgets.QLabel
 	fileText  string
 	hidden    bool
+
+	pressPos   *core.QPoint
+	isDragging bool
 }
 
+// tabModifiedBuffersLua returns the names of the modified buffers shown in the given tabpages
+const tabModifiedBuffersLua = `
+local names, seen = {}, {}
+for _, tab in ipairs({...}) do
+  if vim.api.nvim_tabpage_is_valid(tab) then
+    for _, win in ipairs(vim.api.nvim_tabpage_list_wins(tab)) do
+      local buf = vim.api.nvim_win_get_buf(win)
+      if vim.bo[buf].modified and not seen[buf] then
+        seen[buf] = true
+        local name = vim.api.nvim_buf_get_name(buf)
+        table.insert(names, name == '' and '[No Name]' or vim.fn.fnamemodify(name, ':t'))
+      end
+    end
+  end
+end
+return names
+`
+
+// tabFilesLua returns the Ex commands which restore the working directory and
+// the files shown in the windows of a tabpage
+const tabFilesLua = `
+local tab = ...
+local commands = {'cd ' .. vim.fn.fnameescape(vim.fn.getcwd(-1, vim.api.nvim_tabpage_get_number(tab)))}
+local edit = 'edit '
+for _, win in ipairs(vim.api.nvim_tabpage_list_wins(tab)) do
+  local buf = vim.api.nvim_win_get_buf(win)
+  local name = vim.api.nvim_buf_get_name(buf)
+  if vim.bo[buf].buftype == '' and name ~= '' and vim.api.nvim_win_get_config(win).relative == '' then
+    table.insert(commands, edit .. vim.fn.fnameescape(name))
+    edit = 'split '
+  end
+end
+return commands
+`
+
 func (t *Tabline) subscribe() {
 	if !t.ws.drawTabline {
 		t.widget.Hide()
@@ -80,7 +155,7 @@ func initTabline() *Tabline {
 	widget := widgets.NewQWidget(nil, 0)
 	widget.SetContentsMargins(5, 5, 5, 5)
 
-	layout := util.NewVFlowLayout(16, 5, 1, 0, 0)
+	layout := util.NewVFlowLayout(tablineSpacing, tablinePadding, 1, 0, 0)
 	// layout := widgets.NewQLayout2()
 	// layout.SetSpacing(0)
 	// layout.SetContentsMargins(0, 0, 0, 0)
@@ -119,34 +194,196 @@ func initTabline() *Tabline {
 	// 	return nil
 	// })
 
-	widget.SetLayout(layout)
+	viewport := widgets.NewQWidget(nil, 0)
+	viewport.SetContentsMargins(0, 0, 0, 0)
+	tabsWidget := widgets.NewQWidget(viewport, 0)
+	tabsWidget.SetContentsMargins(0, 0, 0, 0)
+	tabsWidget.SetLayout(layout)
+
+	scrollLeft := newTablineButton()
+	scrollRight := newTablineButton()
+	overflowButton := newTablineButton()
+
+	outerLayout := widgets.NewQHBoxLayout()
+	outerLayout.SetContentsMargins(0, 0, 0, 0)
+	outerLayout.SetSpacing(2)
+	outerLayout.AddWidget(viewport, 1, 0)
+	outerLayout.AddWidget(scrollLeft, 0, 0)
+	outerLayout.AddWidget(scrollRight, 0, 0)
+	outerLayout.AddWidget(overflowButton, 0, 0)
+	widget.SetLayout(outerLayout)
 
 	space := editor.config.Editor.Linespace
 	tabline := &Tabline{
-		widget:       widget,
-		layout:       layout,
-		marginTop:    int(math.Ceil(float64(space) / 3.0)),
-		marginBottom: int(math.Ceil(float64(space) * 2.0 / 3.0)),
-		showtabline:  2,
+		widget:         widget,
+		layout:         layout,
+		viewport:       viewport,
+		tabsWidget:     tabsWidget,
+		scrollLeft:     scrollLeft,
+		scrollRight:    scrollRight,
+		overflowButton: overflowButton,
+		marginTop:      int(math.Ceil(float64(space) / 3.0)),
+		marginBottom:   int(math.Ceil(float64(space) * 2.0 / 3.0)),
+		showtabline:    2,
+		bufferMode:     editor.config.Tabline.Mode == "buffers",
+		thumbnails:     make(map[int]*gui.QPixmap),
 	}
 
-	tabs := []*Tab{}
-	for i := 0; i < 24; i++ {
+	// Tabs are created as needed, starting with the one of the first tabpage
+	tab := tabline.tabAt(0)
+	tab.hidden = false
+	tab.widget.Show()
+
+	scrollLeft.ConnectMousePressEvent(func(*gui.QMouseEvent) {
+		tabline.scrollTo(tabline.offset - tablineScrollStep)
+	})
+	scrollRight.ConnectMousePressEvent(func(*gui.QMouseEvent) {
+		tabline.scrollTo(tabline.offset + tablineScrollStep)
+	})
+	overflowButton.ConnectMousePressEvent(func(event *gui.QMouseEvent) {
+		tabline.showOverflowMenu()
+	})
+	widget.ConnectWheelEvent(tabline.wheelEvent)
+	widget.ConnectResizeEvent(func(*gui.QResizeEvent) {
+		tabline.updateOverflow()
+	})
+
+	return tabline
+}
+
+func newTablineButton() *svg.QSvgWidget {
+	button := svg.NewQSvgWidget(nil)
+	button.SetFixedWidth(editor.iconSize)
+	button.SetFixedHeight(editor.iconSize)
+	cursor := gui.NewQCursor()
+	cursor.SetShape(core.Qt__PointingHandCursor)
+	button.SetCursor(cursor)
+	button.Hide()
+
+	return button
+}
+
+// tabAt returns the i-th tab, creating the tabs up to it as needed
+func (t *Tabline) tabAt(i int) *Tab {
+	for len(t.Tabs) <= i {
 		tab := newTab()
-		tab.t = tabline
-		tabs = append(tabs, tab)
+		tab.t = t
+		tab.hidden = true
+		tab.widget.Hide()
+		if t.font != nil {
+			tab.file.SetFont(t.font)
+		}
+		tab.file.SetContentsMargins(0, t.marginTop, 0, t.marginBottom)
+		t.layout.AddWidget(tab.widget)
+		t.Tabs = append(t.Tabs, tab)
 	}
-	go func() {
-		for i, tab := range tabs {
-			layout.AddWidget(tab.widget)
-			if i > 0 {
-				tab.hide()
-			}
+
+	return t.Tabs[i]
+}
+
+// updateOverflow resizes the tabs to their contents, shows the overflow
+// buttons when they don't fit in the tabline, and scrolls the active tab into view.
+func (t *Tabline) updateOverflow() {
+	width := tablinePadding
+	height := 0
+	activeX, activeWidth := -1, 0
+	activeID := 0
+	for _, tab := range t.Tabs {
+		if tab.hidden {
+			continue
 		}
-	}()
-	tabline.Tabs = tabs
+		size := tab.widget.Size()
+		if tab.active {
+			activeX, activeWidth = width, size.Width()
+			activeID = tab.ID
+		}
+		width += size.Width() + tablineSpacing
+		if size.Height() > height {
+			height = size.Height()
+		}
+	}
+	width += tablinePadding - tablineSpacing
+	t.tabsWidth = width
+	if height > 0 {
+		t.tabsWidget.Resize2(width, height+1)
+		t.viewport.SetFixedHeight(height + 1)
+	}
 
-	return tabline
+	available := t.widget.ContentsRect().Width()
+	isOverflow := width > available
+	for _, button := range []*svg.QSvgWidget{t.scrollLeft, t.scrollRight, t.overflowButton} {
+		if isOverflow {
+			available -= button.Width() + 2
+			button.Show()
+		} else {
+			button.Hide()
+		}
+	}
+	if isOverflow {
+		for _, b := range []struct {
+			button *svg.QSvgWidget
+			name   string
+		}{
+			{t.scrollLeft, "chevron-left"},
+			{t.scrollRight, "chevron-right"},
+			{t.overflowButton, "chevron-down"},
+		} {
+			svgContent := editor.getSvg(b.name, nil)
+			b.button.Load2(core.NewQByteArray2(svgContent, len(svgContent)))
+		}
+	}
+
+	// The active tab is scrolled into view only when it changes,
+	// so that scrolling by hand is not undone by the next update
+	if activeX >= 0 && activeID != t.scrolledToID {
+		t.scrolledToID = activeID
+		if activeX < t.offset {
+			t.offset = activeX - tablinePadding
+		} else if activeX+activeWidth > t.offset+available {
+			t.offset = activeX + activeWidth + tablinePadding - available
+		}
+	}
+	t.scrollTo(t.offset)
+}
+
+// scrollTo scrolls the tabs so that offset pixels are hidden on the left
+func (t *Tabline) scrollTo(offset int) {
+	maxOffset := t.tabsWidth - t.viewport.Width()
+	if offset > maxOffset {
+		offset = maxOffset
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	t.offset = offset
+	t.tabsWidget.Move2(-offset, 0)
+}
+
+func (t *Tabline) wheelEvent(event *gui.QWheelEvent) {
+	delta := event.AngleDelta().Y()
+	if delta == 0 {
+		delta = event.AngleDelta().X()
+	}
+	t.scrollTo(t.offset - delta/2)
+}
+
+// showOverflowMenu lists all the tabs, including the ones scrolled out of view
+func (t *Tabline) showOverflowMenu() {
+	menu := widgets.NewQMenu(t.widget)
+	for _, tab := range t.Tabs {
+		if tab.hidden {
+			continue
+		}
+		tab := tab
+		action := menu.AddAction(tab.file.Text())
+		action.SetCheckable(true)
+		action.SetChecked(tab.active)
+		action.ConnectTriggered(func(checked bool) {
+			tab.switchTo()
+		})
+	}
+	pos := t.overflowButton.MapToGlobal(core.NewQPoint2(0, t.overflowButton.Height()))
+	menu.Exec2(pos, nil)
 }
 
 func newTab() *Tab {
@@ -189,6 +426,8 @@ func newTab() *Tab {
 	tab.widget.ConnectEnterEvent(tab.enterEvent)
 	tab.widget.ConnectLeaveEvent(tab.leaveEvent)
 	tab.widget.ConnectMousePressEvent(tab.pressEvent)
+	tab.widget.ConnectMouseMoveEvent(tab.moveEvent)
+	tab.widget.ConnectMouseReleaseEvent(tab.releaseEvent)
 
 	closeIcon.ConnectMousePressEvent(tab.closeIconPressEvent)
 	closeIcon.ConnectMouseReleaseEvent(tab.closeIconReleaseEvent)
@@ -318,6 +557,7 @@ func (t *Tabline) updateSize() {
 	for _, tab := range t.Tabs {
 		tab.updateSize()
 	}
+	t.updateOverflow()
 }
 
 func (t *Tabline) updateTabs() {
@@ -332,8 +572,27 @@ func (t *Tabline) updateTabs() {
 
 func (t *Tabline) update(args []interface{}) {
 	arg := args[0].([]interface{})
-	t.CurrentID = int(arg[0].(nvim.Tabpage))
+	var lenshowntabs int
+	if t.bufferMode {
+		if len(arg) > 3 {
+			t.hasBufferList = true
+			t.setBufferList(arg[2], arg[3])
+		}
+		lenshowntabs = t.showBuffers()
+	} else {
+		lenshowntabs = t.showTabpages(arg)
+	}
+	t.updateVisibility(lenshowntabs)
+}
+
+// showTabpages shows the tabpages of tabline_update and returns the number of shown tabs
+func (t *Tabline) showTabpages(arg []interface{}) int {
+	currentID := int(arg[0].(nvim.Tabpage))
 	tabs := arg[1].([]interface{})
+	if editor.config.Tabline.ShowThumbnail {
+		t.updateThumbnails(currentID, tabs)
+	}
+	t.CurrentID = currentID
 	if len(tabs) == 1 {
 		t.Tabs[0].setActive(false)
 		t.Tabs[0].updateStyle()
@@ -343,11 +602,8 @@ func (t *Tabline) update(args []interface{}) {
 		if !ok {
 			continue
 		}
-		if i > len(t.Tabs)-1 {
-			return
-		}
 
-		tab := t.Tabs[i]
+		tab := t.tabAt(i)
 		tab.ID = int(tabMap["tab"].(nvim.Tabpage))
 		text := tabMap["name"].(string)
 		tab.setActive(tab.ID == t.CurrentID)
@@ -369,14 +625,45 @@ func (t *Tabline) update(args []interface{}) {
 		tab.show()
 	}
 
+	return t.hideTabsFrom(len(tabs))
+}
+
+// updateThumbnails keeps the image of the tabpage being left. The grids
+// still hold its windows, since tabline_update comes before their redraw.
+func (t *Tabline) updateThumbnails(currentID int, tabs []interface{}) {
+	if t.CurrentID != 0 && currentID != t.CurrentID {
+		t.thumbnails[t.CurrentID] = t.ws.screen.grabThumbnail()
+	}
+	ids := make(map[int]bool, len(tabs))
+	for _, tabInterface := range tabs {
+		if tabMap, ok := tabInterface.(map[string]interface{}); ok {
+			if id, ok := tabMap["tab"].(nvim.Tabpage); ok {
+				ids[int(id)] = true
+			}
+		}
+	}
+	for id := range t.thumbnails {
+		if !ids[id] {
+			delete(t.thumbnails, id)
+		}
+	}
+}
+
+// hideTabsFrom hides the tabs from index start and returns the number of shown tabs
+func (t *Tabline) hideTabsFrom(start int) int {
 	lenhiddentabs := 0
-	for i := len(tabs); i < len(t.Tabs); i++ {
+	for i := start; i < len(t.Tabs); i++ {
 		tab := t.Tabs[i]
 		tab.setActive(false)
 		tab.hide()
 		lenhiddentabs++
 	}
-	lenshowntabs := 24 - lenhiddentabs
+
+	return len(t.Tabs) - lenhiddentabs
+}
+
+func (t *Tabline) updateVisibility(lenshowntabs int) {
+	t.updateOverflow()
 
 	// Set color
 	t.setColor()
@@ -405,6 +692,181 @@ func (t *Tabline) update(args []interface{}) {
 	}
 }
 
+// setBufferList sets the listed buffers from the buffer list of tabline_update,
+// keeping the flags of the buffers already known.
+func (t *Tabline) setBufferList(curbuf interface{}, list interface{}) {
+	if buf, ok := curbuf.(nvim.Buffer); ok {
+		t.CurrentID = int(buf)
+	}
+	items, ok := list.([]interface{})
+	if !ok {
+		return
+	}
+	known := make(map[int]*tablineBuffer, len(t.buffers))
+	for _, b := range t.buffers {
+		known[b.id] = b
+	}
+	var buffers []*tablineBuffer
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		buf, ok := m["buffer"].(nvim.Buffer)
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		b, ok := known[int(buf)]
+		if !ok {
+			b = &tablineBuffer{id: int(buf)}
+		}
+		b.name = name
+		buffers = append(buffers, b)
+	}
+	t.buffers = buffers
+}
+
+// updateBuffers handles the gonvim_buffers event, which is sent with the
+// current buffer and the listed buffers as [bufnr, name, modified, readonly].
+func (t *Tabline) updateBuffers(args []interface{}) {
+	if !t.bufferMode || len(args) < 2 {
+		return
+	}
+	items, ok := args[1].([]interface{})
+	if !ok {
+		return
+	}
+	var buffers []*tablineBuffer
+	for _, item := range items {
+		info, ok := item.([]interface{})
+		if !ok || len(info) < 4 {
+			continue
+		}
+		name, _ := info[1].(string)
+		buffers = append(buffers, &tablineBuffer{
+			id:       util.ReflectToInt(info[0]),
+			name:     name,
+			modified: util.ReflectToInt(info[2]) != 0,
+			readonly: util.ReflectToInt(info[3]) != 0,
+		})
+	}
+
+	if t.hasBufferList {
+		// The list itself is kept up to date by tabline_update
+		flags := make(map[int]*tablineBuffer, len(buffers))
+		for _, b := range buffers {
+			flags[b.id] = b
+		}
+		for _, b := range t.buffers {
+			if f, ok := flags[b.id]; ok {
+				b.modified = f.modified
+				b.readonly = f.readonly
+			}
+		}
+	} else {
+		t.CurrentID = util.ReflectToInt(args[0])
+		t.buffers = buffers
+	}
+	t.updateVisibility(t.showBuffers())
+}
+
+// showBuffers shows the listed buffers and returns the number of shown tabs
+func (t *Tabline) showBuffers() int {
+	paths := make([]string, len(t.buffers))
+	for i, b := range t.buffers {
+		paths[i] = b.name
+	}
+	names := disambiguateNames(paths)
+
+	shown := 0
+	for i, b := range t.buffers {
+		tab := t.tabAt(i)
+		tab.ID = b.id
+		tab.setActive(b.id == t.CurrentID)
+
+		tab.fileType = getFileType(b.name)
+		if editor.config.Tabline.ShowIcon {
+			tab.updateFileIcon()
+		}
+
+		text := names[i]
+		if b.modified {
+			text += " [+]"
+		}
+		if b.readonly {
+			text += " [RO]"
+		}
+		if text != tab.fileText {
+			tab.fileText = text
+			tab.file.SetText(text)
+			tab.updateSize()
+		}
+
+		if b.id == t.CurrentID {
+			t.currentFileText = b.name
+		}
+		tab.show()
+		shown++
+	}
+
+	return t.hideTabsFrom(shown)
+}
+
+// disambiguateNames returns the file names of paths, prefixed with as many
+// parent directories as needed to tell apart the files of the same name.
+func disambiguateNames(paths []string) []string {
+	parts := make([][]string, len(paths))
+	depth := make([]int, len(paths))
+	for i, path := range paths {
+		if path == "" {
+			continue
+		}
+		parts[i] = strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+		depth[i] = 1
+	}
+	label := func(i int) string {
+		return strings.Join(parts[i][len(parts[i])-depth[i]:], "/")
+	}
+
+	for {
+		groups := make(map[string][]int)
+		for i := range paths {
+			if parts[i] == nil {
+				continue
+			}
+			l := label(i)
+			groups[l] = append(groups[l], i)
+		}
+		isChanged := false
+		for _, indexes := range groups {
+			if len(indexes) < 2 {
+				continue
+			}
+			for _, i := range indexes {
+				if depth[i] < len(parts[i]) {
+					depth[i]++
+					isChanged = true
+				}
+			}
+		}
+		if !isChanged {
+			break
+		}
+	}
+
+	names := make([]string, len(paths))
+	for i := range paths {
+		if parts[i] == nil {
+			names[i] = "[No Name]"
+			continue
+		}
+		names[i] = label(i)
+	}
+
+	return names
+}
+
 func getFileType(text string) string {
 	if strings.HasPrefix(text, "term://") {
 		return "terminal"
@@ -441,17 +903,324 @@ func getFileType(text string) string {
 
 func (t *Tab) enterEvent(event *core.QEvent) {
 	t.closeIcon.Show()
+	if !editor.config.Tabline.ShowThumbnail || t.t.bufferMode {
+		return
+	}
+	id := t.ID
+	pos := t.widget.MapToGlobal(core.NewQPoint2(0, t.widget.Height()))
+	editor.showThumbnail(pos, func() *gui.QPixmap {
+		if id == t.t.CurrentID {
+			return t.t.ws.screen.grabThumbnail()
+		}
+		return t.t.thumbnails[id]
+	})
 }
 
 func (t *Tab) leaveEvent(event *core.QEvent) {
 	t.closeIcon.Hide()
+	editor.hideThumbnail()
 }
 
 func (t *Tab) pressEvent(event *gui.QMouseEvent) {
+	editor.hideThumbnail()
+	switch event.Button() {
+	case core.Qt__MiddleButton:
+		t.close()
+		return
+	case core.Qt__RightButton:
+		t.showMenu(event.GlobalPos())
+		return
+	}
+	if !t.t.bufferMode {
+		t.pressPos = event.GlobalPos()
+	}
+	t.switchTo()
+}
+
+// switchTo makes the tabpage or buffer of the tab current
+func (t *Tab) switchTo() {
+	if t.t.bufferMode {
+		go t.t.ws.nvim.Command(fmt.Sprintf("buffer %d", t.ID))
+		return
+	}
 	targetTab := nvim.Tabpage(t.ID)
 	go t.t.ws.nvim.SetCurrentTabpage(targetTab)
 }
 
+func (t *Tab) moveEvent(event *gui.QMouseEvent) {
+	if t.t.bufferMode || t.pressPos == nil || t.isDragging {
+		return
+	}
+	pos := event.GlobalPos()
+	distance := math.Abs(float64(pos.X()-t.pressPos.X())) + math.Abs(float64(pos.Y()-t.pressPos.Y()))
+	if int(distance) < widgets.QApplication_StartDragDistance() {
+		return
+	}
+	t.isDragging = true
+	cursor := gui.NewQCursor()
+	cursor.SetShape(core.Qt__ClosedHandCursor)
+	t.widget.SetCursor(cursor)
+}
+
+// releaseEvent moves the tab to where it was dropped
+func (t *Tab) releaseEvent(event *gui.QMouseEvent) {
+	isDragging := t.isDragging
+	t.pressPos = nil
+	t.isDragging = false
+	if !isDragging {
+		return
+	}
+	cursor := gui.NewQCursor()
+	cursor.SetShape(core.Qt__ArrowCursor)
+	t.widget.SetCursor(cursor)
+
+	from := t.t.indexOf(t)
+	to := t.t.tabIndexAt(t.t.tabsWidget.MapFromGlobal(event.GlobalPos()))
+	if from < 0 || to < 0 || from == to {
+		return
+	}
+	targetTab := nvim.Tabpage(t.ID)
+	go func() {
+		t.t.ws.nvim.SetCurrentTabpage(targetTab)
+		t.t.ws.nvim.Command(fmt.Sprintf("tabmove %d", tabMoveArg(from, to)))
+	}()
+}
+
+// tabMoveArg returns the argument of :tabmove which moves the tab at the
+// 0-based index from to the index to. :tabmove N puts the tab after the
+// Nth tab counted before the move.
+func tabMoveArg(from, to int) int {
+	if to > from {
+		return to + 1
+	}
+
+	return to
+}
+
+// indexOf returns the position of tab in the tabline
+func (t *Tabline) indexOf(tab *Tab) int {
+	for i, tb := range t.Tabs {
+		if tb == tab {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// tabIndexAt returns the index of the shown tab at pos in tabsWidget
+func (t *Tabline) tabIndexAt(pos *core.QPoint) int {
+	for i, tab := range t.Tabs {
+		if tab.hidden {
+			continue
+		}
+		if tab.widget.Geometry().Contains(pos, false) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// shownTabIDs returns the tabpages in the tabline
+func (t *Tabline) shownTabIDs() []int {
+	var ids []int
+	for _, tab := range t.Tabs {
+		if !tab.hidden {
+			ids = append(ids, tab.ID)
+		}
+	}
+
+	return ids
+}
+
+func (t *Tab) showMenu(pos *core.QPoint) {
+	ids := t.t.shownTabIDs()
+	index := t.t.indexOf(t)
+
+	var others []int
+	for _, id := range ids {
+		if id != t.ID {
+			others = append(others, id)
+		}
+	}
+
+	menu := widgets.NewQMenu(t.widget)
+	menu.AddAction("Close").ConnectTriggered(func(checked bool) {
+		t.close()
+	})
+	closeOthers := menu.AddAction("Close Others")
+	closeOthers.SetEnabled(len(ids) > 1)
+	closeOthers.ConnectTriggered(func(checked bool) {
+		if t.t.bufferMode {
+			t.t.closeBuffers(others)
+			return
+		}
+		t.t.closeTabs(t.ID, others, "tabonly!")
+	})
+	closeRight := menu.AddAction("Close to the Right")
+	closeRight.SetEnabled(index >= 0 && index < len(ids)-1)
+	closeRight.ConnectTriggered(func(checked bool) {
+		if t.t.bufferMode {
+			t.t.closeBuffers(ids[index+1:])
+			return
+		}
+		// :tabclose closes a single tabpage even with a range, so the
+		// last one is closed as many times as there are tabpages to the right
+		right := ids[index+1:]
+		commands := make([]string, len(right))
+		for i := range right {
+			commands[i] = "$tabclose!"
+		}
+		t.t.closeTabs(t.ID, right, strings.Join(commands, " | "))
+	})
+	if t.t.bufferMode {
+		menu.Exec2(pos, nil)
+		return
+	}
+	menu.AddSeparator()
+	menu.AddAction("Duplicate").ConnectTriggered(func(checked bool) {
+		targetTab := nvim.Tabpage(t.ID)
+		go func() {
+			t.t.ws.nvim.SetCurrentTabpage(targetTab)
+			t.t.ws.nvim.Command("tab split")
+		}()
+	})
+	moveToWorkspace := menu.AddAction("Move to New Workspace")
+	moveToWorkspace.SetEnabled(len(editor.workspaces) < WORKSPACELEN)
+	moveToWorkspace.ConnectTriggered(func(checked bool) {
+		t.moveToNewWorkspace(len(ids) > 1)
+	})
+
+	menu.Exec2(pos, nil)
+}
+
+// close closes the tab, after confirming it if the tab holds modified buffers
+func (t *Tab) close() {
+	if t.t.bufferMode {
+		t.t.closeBuffers([]int{t.ID})
+		return
+	}
+	if t.ID == 1 {
+		t.t.closeTabs(t.ID, []int{t.ID}, "q")
+	} else {
+		t.t.closeTabs(t.ID, []int{t.ID}, "tabclose!")
+	}
+}
+
+// closeTabs makes current the current tabpage and runs command, which closes
+// the tabpages ids. If they hold modified buffers, it asks for confirmation first.
+func (t *Tabline) closeTabs(current int, ids []int, command string) {
+	run := func() {
+		t.ws.nvim.SetCurrentTabpage(nvim.Tabpage(current))
+		t.ws.nvim.Command(command)
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	go func() {
+		var modified []string
+		err := t.ws.nvim.ExecLua(tabModifiedBuffersLua, &modified, args...)
+		if err != nil {
+			modified = nil
+		}
+		confirmClose(modified, run)
+	}()
+}
+
+// closeBuffers deletes the buffers ids. If some of them are modified, it asks for confirmation first.
+func (t *Tabline) closeBuffers(ids []int) {
+	var modified []string
+	var bufnrs []string
+	for _, id := range ids {
+		bufnrs = append(bufnrs, fmt.Sprintf("%d", id))
+		for _, b := range t.buffers {
+			if b.id == id && b.modified {
+				name := filepath.Base(b.name)
+				if b.name == "" {
+					name = "[No Name]"
+				}
+				modified = append(modified, name)
+			}
+		}
+	}
+	if len(bufnrs) == 0 {
+		return
+	}
+	command := "bdelete! " + strings.Join(bufnrs, " ")
+	go confirmClose(modified, func() {
+		t.ws.nvim.Command(command)
+	})
+}
+
+// confirmClose runs closer right away if there are no modified buffers,
+// otherwise it asks whether to close them and lose their changes.
+func confirmClose(modified []string, closer func()) {
+	if len(modified) == 0 {
+		closer()
+		return
+	}
+	message := fmt.Sprintf("[Goneovim] %s has unsaved changes. Do you want to close it anyway?", strings.Join(modified, ", "))
+	opts := []*NotifyButton{
+		{
+			action: closer,
+			text:   "Close",
+		},
+		{
+			action: func() {},
+			text:   "Cancel",
+		},
+	}
+	editor.pushNotification(NotifyWarn, 0, message, notifyOptionArg(opts))
+}
+
+// moveToNewWorkspace opens the files of the tab in a new workspace, and
+// closes the tab unless it is the last one. The files are read from disk, so
+// that a tab with modified buffers is not moved.
+func (t *Tab) moveToNewWorkspace(closeTab bool) {
+	go func() {
+		var modified []string
+		err := t.t.ws.nvim.ExecLua(tabModifiedBuffersLua, &modified, t.ID)
+		if err != nil {
+			return
+		}
+		if len(modified) > 0 {
+			editor.pushNotification(
+				NotifyWarn,
+				-1,
+				fmt.Sprintf("[Goneovim] Save %s before moving the tab to a new workspace.", strings.Join(modified, ", ")),
+			)
+			return
+		}
+		var commands []string
+		err = t.t.ws.nvim.ExecLua(tabFilesLua, &commands, t.ID)
+		if err != nil {
+			return
+		}
+
+		// The new workspace sources the script once nvim is attached
+		file, err := ioutil.TempFile("", "goneovim-tab-*.vim")
+		if err != nil {
+			return
+		}
+		commands = append(commands, `call delete(expand("<sfile>"))`)
+		_, err = file.WriteString(strings.Join(commands, "\n") + "\n")
+		file.Close()
+		if err != nil {
+			os.Remove(file.Name())
+			return
+		}
+
+		editor.workspaceScripts <- file.Name()
+		editor.signal.WorkspaceScriptSignal()
+		if closeTab {
+			t.t.closeTabs(t.ID, []int{t.ID}, "tabclose!")
+		}
+	}()
+}
+
 func (t *Tab) closeIconPressEvent(event *gui.QMouseEvent) {
 	t.closeIcon.SetFixedWidth(editor.iconSize)
 	t.closeIcon.SetFixedHeight(editor.iconSize)
@@ -460,15 +1229,7 @@ func (t *Tab) closeIconPressEvent(event *gui.QMouseEvent) {
 }
 
 func (t *Tab) closeIconReleaseEvent(event *gui.QMouseEvent) {
-	if t.ID == 1 {
-		go t.t.ws.nvim.Command(fmt.Sprintf("q"))
-	} else {
-		targetTab := nvim.Tabpage(t.ID)
-		go func() {
-			t.t.ws.nvim.SetCurrentTabpage(targetTab)
-			t.t.ws.nvim.Command("tabclose!")
-		}()
-	}
+	t.close()
 }
 
 func (t *Tab) closeIconEnterEvent(event *core.QEvent) {
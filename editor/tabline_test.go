@@ -0,0 +1,65 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTabMoveArg(t *testing.T) {
+	tests := []struct {
+		name string
+		from int
+		to   int
+		want int
+	}{
+		{"move to the first", 3, 0, 0},
+		{"move left", 3, 1, 1},
+		{"move right", 0, 2, 3},
+		{"move to the last", 1, 4, 5},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tabMoveArg(tt.from, tt.to); got != tt.want {
+				t.Errorf("tabMoveArg(%d, %d) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisambiguateNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			"unique names",
+			[]string{"/src/a.go", "/src/b.go"},
+			[]string{"a.go", "b.go"},
+		},
+		{
+			"same names in different directories",
+			[]string{"/src/cmd/main.go", "/src/tools/main.go", "/src/util.go"},
+			[]string{"cmd/main.go", "tools/main.go", "util.go"},
+		},
+		{
+			"same parent directory names",
+			[]string{"/a/x/main.go", "/b/x/main.go"},
+			[]string{"a/x/main.go", "b/x/main.go"},
+		},
+		{
+			"unnamed buffers",
+			[]string{"", "/src/a.go", ""},
+			[]string{"[No Name]", "a.go", "[No Name]"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := disambiguateNames(tt.paths); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("disambiguateNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const (
+	thumbnailWidth = 320
+	// thumbnailDelay is the time in milliseconds the pointer stays on an item before its thumbnail is shown
+	thumbnailDelay = 500
+	// thumbnailInterval is the time in milliseconds between the thumbnails
+	// grabbed from a workspace redrawn in the background
+	thumbnailInterval = 1000
+)
+
+// Thumbnail is the popup which shows a scaled preview of the screen of a tabpage or a workspace
+type Thumbnail struct {
+	widget *widgets.QLabel
+	timer  *core.QTimer
+	pos    *core.QPoint
+	source func() *gui.QPixmap
+}
+
+func newThumbnail() *Thumbnail {
+	widget := widgets.NewQLabel(nil, core.Qt__ToolTip|core.Qt__FramelessWindowHint)
+	widget.SetContentsMargins(0, 0, 0, 0)
+	widget.SetAttribute(core.Qt__WA_ShowWithoutActivating, true)
+
+	timer := core.NewQTimer(nil)
+	timer.SetSingleShot(true)
+
+	th := &Thumbnail{
+		widget: widget,
+		timer:  timer,
+	}
+	timer.ConnectTimeout(th.popup)
+
+	return th
+}
+
+// grabThumbnail returns the scaled image of the windows painted on the screen
+func (s *Screen) grabThumbnail() *gui.QPixmap {
+	if s.widget.Width() <= 0 || s.widget.Height() <= 0 {
+		return nil
+	}
+
+	return s.widget.Grab(s.widget.Rect()).Scaled2(
+		thumbnailWidth,
+		thumbnailWidth,
+		core.Qt__KeepAspectRatio,
+		core.Qt__SmoothTransformation,
+	)
+}
+
+// refreshThumbnail grabs the thumbnail of the workspace redrawn in the
+// background, at most once in thumbnailInterval
+func (w *Workspace) refreshThumbnail() {
+	if !editor.config.SideBar.ShowThumbnail || editor.active >= len(editor.workspaces) || editor.workspaces[editor.active] == w {
+		return
+	}
+	if w.thumbnailTimer == nil {
+		w.thumbnailTimer = core.NewQTimer(nil)
+		w.thumbnailTimer.SetSingleShot(true)
+		w.thumbnailTimer.ConnectTimeout(func() {
+			if editor.active < len(editor.workspaces) && editor.workspaces[editor.active] != w {
+				w.thumbnail = w.screen.grabThumbnail()
+			}
+		})
+	}
+	if !w.thumbnailTimer.IsActive() {
+		w.thumbnailTimer.Start(thumbnailInterval)
+	}
+}
+
+// showThumbnail shows the image returned by source at the global position pos,
+// once the pointer has stayed there for a while.
+func (e *Editor) showThumbnail(pos *core.QPoint, source func() *gui.QPixmap) {
+	if e.thumbnail == nil {
+		e.thumbnail = newThumbnail()
+	}
+	e.thumbnail.pos = pos
+	e.thumbnail.source = source
+	e.thumbnail.timer.Start(thumbnailDelay)
+}
+
+func (e *Editor) hideThumbnail() {
+	if e.thumbnail == nil {
+		return
+	}
+	e.thumbnail.timer.Stop()
+	e.thumbnail.widget.Hide()
+}
+
+func (th *Thumbnail) popup() {
+	if th.source == nil {
+		return
+	}
+	pixmap := th.source()
+	if pixmap == nil || pixmap.IsNull() {
+		return
+	}
+	th.widget.SetStyleSheet(fmt.Sprintf(
+		" * { border: 1px solid %s; background-color: %s; }",
+		editor.colors.inactiveFg.String(),
+		editor.colors.widgetBg.String(),
+	))
+	th.widget.SetPixmap(pixmap)
+	th.widget.AdjustSize()
+	th.widget.Move(th.pos)
+	th.widget.Show()
+	th.widget.Raise()
+}
@@ -0,0 +1,246 @@
+package editor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const (
+	windowScrollBarWidth    = 8
+	windowScrollBarMinThumb = 16
+	// windowScrollBarPeriod is the time in milliseconds the scrollbar stays visible after scrolling
+	windowScrollBarPeriod = 1200
+)
+
+// WindowScrollBar is the overlay scrollbar on the right edge of a window
+type WindowScrollBar struct {
+	win       *Window
+	widget    *widgets.QWidget
+	effect    *widgets.QGraphicsOpacityEffect
+	animation *core.QPropertyAnimation
+	timer     *core.QTimer
+
+	topLine   int
+	botLine   int
+	lineCount int
+
+	isShown    bool
+	isHovered  bool
+	isDragging bool
+	dragOffset int
+}
+
+func newWindowScrollBar(win *Window) *WindowScrollBar {
+	widget := widgets.NewQWidget(win, 0)
+	widget.SetContentsMargins(0, 0, 0, 0)
+	widget.SetFixedWidth(windowScrollBarWidth)
+	widget.SetAttribute(core.Qt__WA_TranslucentBackground, true)
+	// The faded out scrollbar lets clicks through to the window
+	widget.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+
+	effect := widgets.NewQGraphicsOpacityEffect(widget)
+	effect.SetOpacity(0)
+	widget.SetGraphicsEffect(effect)
+
+	animation := core.NewQPropertyAnimation2(effect, core.NewQByteArray2("opacity", len("opacity")), widget)
+	animation.SetDuration(200)
+	animation.SetEasingCurve(core.NewQEasingCurve(core.QEasingCurve__InOutQuad))
+
+	timer := core.NewQTimer(nil)
+	timer.SetSingleShot(true)
+
+	s := &WindowScrollBar{
+		win:       win,
+		widget:    widget,
+		effect:    effect,
+		animation: animation,
+		timer:     timer,
+	}
+
+	timer.ConnectTimeout(func() {
+		if s.isHovered || s.isDragging {
+			return
+		}
+		s.fade(false)
+	})
+	widget.ConnectPaintEvent(s.paint)
+	widget.ConnectEnterEvent(func(e *core.QEvent) {
+		s.isHovered = true
+		s.fade(true)
+	})
+	widget.ConnectLeaveEvent(func(e *core.QEvent) {
+		s.isHovered = false
+		s.timer.Start(windowScrollBarPeriod)
+	})
+	widget.ConnectMousePressEvent(s.mousePress)
+	widget.ConnectMouseMoveEvent(s.mouseMove)
+	widget.ConnectMouseReleaseEvent(func(e *gui.QMouseEvent) {
+		s.isDragging = false
+		s.timer.Start(windowScrollBarPeriod)
+	})
+	widget.Hide()
+
+	return s
+}
+
+// setWindowViewports updates the scrollbars of the windows from win_viewport events
+func (s *Screen) setWindowViewports(args []interface{}) {
+	if !editor.config.ScrollBar.PerWindow {
+		return
+	}
+	for _, arg := range args {
+		vp := arg.([]interface{})
+		win, ok := s.getWindow(util.ReflectToInt(vp[0]))
+		if !ok || win.grid == 1 || win.isMsgGrid || win.isFloatWin || win.isExternal {
+			continue
+		}
+
+		// The line count is sent by nvim 0.7 and later
+		lineCount := 0
+		if len(vp) > 6 {
+			lineCount = util.ReflectToInt(vp[6])
+		} else if win.grid == s.ws.cursor.gridid {
+			lineCount = s.ws.maxLine
+		}
+
+		if win.scrollBar == nil {
+			win.scrollBar = newWindowScrollBar(win)
+		}
+		win.scrollBar.setViewport(
+			util.ReflectToInt(vp[2])+1,
+			util.ReflectToInt(vp[3]),
+			lineCount,
+		)
+	}
+}
+
+// setViewport moves the thumb to the viewport of the window and shows the scrollbar for a while
+func (s *WindowScrollBar) setViewport(topLine, botLine, lineCount int) {
+	isScrolled := topLine != s.topLine
+	s.topLine = topLine
+	s.botLine = botLine
+	if lineCount > 0 {
+		s.lineCount = lineCount
+	}
+
+	if s.lineCount <= s.botLine-s.topLine+1 {
+		s.widget.Hide()
+		return
+	}
+	s.widget.SetFixedHeight(s.win.Height())
+	s.widget.Move2(s.win.Width()-windowScrollBarWidth, 0)
+	s.widget.Show()
+	s.widget.Raise()
+	s.widget.Update()
+
+	if isScrolled {
+		s.fade(true)
+		s.timer.Start(windowScrollBarPeriod)
+	}
+}
+
+// fade fades the scrollbar in or out. The scrollbar takes mouse events only
+// while it is faded in, so that the columns under it can be clicked otherwise.
+func (s *WindowScrollBar) fade(in bool) {
+	if s.isShown == in {
+		return
+	}
+	s.isShown = in
+	s.widget.SetAttribute(core.Qt__WA_TransparentForMouseEvents, !in)
+	end := 0.0
+	if in {
+		end = 1.0
+	}
+	s.animation.Stop()
+	s.animation.SetStartValue(core.NewQVariant5(s.effect.Opacity()))
+	s.animation.SetEndValue(core.NewQVariant5(end))
+	s.animation.Start(core.QAbstractAnimation__KeepWhenStopped)
+}
+
+// thumbRect returns the position and height of the thumb
+func (s *WindowScrollBar) thumbRect() (int, int) {
+	height := s.widget.Height()
+	if s.lineCount == 0 {
+		return 0, height
+	}
+	thumbHeight := int(float64(s.botLine-s.topLine+1) / float64(s.lineCount) * float64(height))
+	if thumbHeight < windowScrollBarMinThumb {
+		thumbHeight = windowScrollBarMinThumb
+	}
+	maxTop := s.lineCount - (s.botLine - s.topLine + 1)
+	if maxTop < 1 {
+		return 0, thumbHeight
+	}
+	y := int(float64(s.topLine-1) / float64(maxTop) * float64(height-thumbHeight))
+
+	return y, thumbHeight
+}
+
+func (s *WindowScrollBar) paint(event *gui.QPaintEvent) {
+	p := gui.NewQPainter2(s.widget)
+	defer p.DestroyQPainter()
+	p.SetRenderHint(gui.QPainter__Antialiasing, true)
+
+	color := editor.colors.scrollBarFg
+	if s.isHovered || s.isDragging {
+		color = hexToRGBA(editor.config.SideBar.AccentColor)
+	}
+	y, height := s.thumbRect()
+	path := gui.NewQPainterPath()
+	path.AddRoundedRect2(
+		2, float64(y),
+		float64(windowScrollBarWidth-3), float64(height),
+		3, 3,
+		core.Qt__AbsoluteSize,
+	)
+	p.FillPath(path, gui.NewQBrush3(color.QColor(), core.Qt__SolidPattern))
+}
+
+func (s *WindowScrollBar) mousePress(e *gui.QMouseEvent) {
+	if e.Button() != core.Qt__LeftButton {
+		return
+	}
+	y, height := s.thumbRect()
+	s.isDragging = true
+	if e.Y() >= y && e.Y() <= y+height {
+		s.dragOffset = e.Y() - y
+		return
+	}
+	// Pressing the track centers the thumb on the pointer
+	s.dragOffset = height / 2
+	s.scrollTo(e.Y())
+}
+
+func (s *WindowScrollBar) mouseMove(e *gui.QMouseEvent) {
+	if !s.isDragging {
+		return
+	}
+	s.scrollTo(e.Y())
+}
+
+// scrollTo scrolls the window so that the top of the thumb is at y - dragOffset.
+// The window is scrolled without entering it, so the focus stays where it is.
+func (s *WindowScrollBar) scrollTo(y int) {
+	_, height := s.thumbRect()
+	track := s.widget.Height() - height
+	if track <= 0 {
+		return
+	}
+	maxTop := s.lineCount - (s.botLine - s.topLine + 1)
+	ratio := math.Max(0, math.Min(1, float64(y-s.dragOffset)/float64(track)))
+	topLine := int(math.Round(ratio*float64(maxTop))) + 1
+	if topLine == s.topLine {
+		return
+	}
+
+	go s.win.s.ws.nvim.ExecLua(fmt.Sprintf(
+		"vim.api.nvim_win_call(%d, function() vim.fn.winrestview({topline = %d}) end)",
+		s.win.id,
+		topLine,
+	), nil)
+}
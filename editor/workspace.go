@@ -42,6 +42,7 @@ type workspaceSignal struct {
 	_ func() `signal:"messageSignal"`
 
 	_ func() `signal:"markdownSignal"`
+	_ func() `signal:"markdownExportSignal"`
 
 	_ func() `signal:"lazyDrawSignal"`
 }
@@ -51,6 +52,9 @@ type Workspace struct {
 	widget    *widgets.QWidget
 	layout2   *widgets.QHBoxLayout
 	hasLazyUI bool
+	thumbnail *gui.QPixmap
+	// thumbnailTimer throttles the thumbnails grabbed in the background
+	thumbnailTimer *core.QTimer
 
 	font       *Font
 	fontwide   *Font
@@ -68,6 +72,13 @@ type Workspace struct {
 	cmdline   *Cmdline
 	signature *Signature
 	message   *Message
+	msgStatus *MessageStatus
+	msgLog    []*MessageLogEntry
+	msgLogCwd string
+	dialog    *Dialog
+	bellFlash *widgets.QWidget
+	menu      *Menu
+	bellTimer *core.QTimer
 	minimap   *MiniMap
 
 	width  int
@@ -121,6 +132,7 @@ type Workspace struct {
 	drawStatusline bool
 	drawTabline    bool
 	drawLint       bool
+	isMouseEnabled bool
 }
 
 func newWorkspace(path string) (*Workspace, error) {
@@ -134,6 +146,8 @@ func newWorkspace(path string) (*Workspace, error) {
 		foreground:    newRGBA(255, 255, 255, 1),
 		background:    newRGBA(0, 0, 0, 1),
 		special:       newRGBA(255, 255, 255, 1),
+		// nvim sends mouse_off if 'mouse' disables it
+		isMouseEnabled: true,
 	}
 	w.registerSignal()
 
@@ -195,6 +209,10 @@ func newWorkspace(path string) (*Workspace, error) {
 		w.statusline.ws = w
 	}
 
+	// menu
+	w.menu = newMenu()
+	w.menu.ws = w
+
 	// // Lint
 	// if editor.config.Lint.Visible {
 	// 	w.loc = initLocpopup()
@@ -232,6 +250,9 @@ func newWorkspace(path string) (*Workspace, error) {
 	widget2.SetLayout(w.layout2)
 
 	// assemble all neovim ui components
+	if editor.config.Menu.Visible {
+		layout.AddWidget(w.menu.bar, 0, 0)
+	}
 	if editor.config.Editor.ExtTabline {
 		layout.AddWidget(w.tabline.widget, 0, 0)
 	}
@@ -301,18 +322,14 @@ func (w *Workspace) lazyDrawUI() {
 		w.markdown.webview.SetParent(w.screen.widget)
 	}
 
-	// Asynchronously execute the process for minimap
+	// Draw the minimap from the current buffer
 	go func() {
-		if !w.uiRemoteAttached && !editor.config.MiniMap.Disable {
-			w.minimap.startMinimapProc()
-			time.Sleep(time.Millisecond * 50)
+		if !editor.config.MiniMap.Disable {
 			w.minimap.mu.Lock()
 			isMinimapVisible := w.minimap.visible
 			w.minimap.mu.Unlock()
 			if isMinimapVisible {
-				w.minimap.setCurrentRegion()
 				w.minimap.bufUpdate()
-				w.minimap.bufSync()
 			}
 		}
 	}()
@@ -405,6 +422,7 @@ func (w *Workspace) registerSignal() {
 			editor.close()
 			return
 		}
+		w.saveMessageLog()
 		editor.workspaces = workspaces
 
 		items := []*WorkspaceSideItem{}
@@ -470,9 +488,11 @@ func (w *Workspace) startNvim(path string) error {
 
 	runtimepath := getResourcePath() + "/runtime/"
 	s := fmt.Sprintf("let &rtp.=',%s'", runtimepath)
+	option = append(option, "--cmd")
+	option = append(option, s)
 	if editor.config.Popupmenu.ShowDigit {
 		option = append(option, "--cmd")
-		option = append(option, s)
+		option = append(option, "let g:gonvim_popupmenu_showdigit=1")
 	}
 	option = append(option, "--embed")
 	childProcessArgs := nvim.ChildProcessArgs(
@@ -615,6 +635,7 @@ func (w *Workspace) configure() {
 func (w *Workspace) attachUI(path string) error {
 	go w.nvim.Subscribe("Gui")
 	go w.initGonvim()
+	go w.registerGoneovimLua()
 	if w.tabline != nil {
 		w.tabline.subscribe()
 	}
@@ -663,17 +684,24 @@ func (w *Workspace) initGonvim() {
 	au GonvimAuFilepath BufEnter,TabEnter,DirChanged,TermOpen,TermClose * silent call rpcnotify(0, "Gui", "gonvim_workspace_filepath", expand("%:p"))
 	`
 	if !editor.config.Markdown.Disable {
-		gonvimAutoCmds += `
+		filetypes, imagePatterns := previewAutoCmdPatterns()
+		gonvimAutoCmds += fmt.Sprintf(`
 		aug GonvimAuMd | au! | aug END
-		au  GonvimAuMd TextChanged,TextChangedI * if &ft == "markdown" | call rpcnotify(0, "Gui", "gonvim_markdown_update") | endif
-		au GonvimAuMd BufEnter *.md call rpcnotify(0, "Gui", "gonvim_markdown_new_buffer")
-		`
+		au  GonvimAuMd TextChanged,TextChangedI * if index(%[1]s, &ft) >= 0 | call rpcnotify(0, "Gui", "gonvim_markdown_update") | endif
+		au GonvimAuMd BufEnter * if index(%[1]s, &ft) >= 0 | call rpcnotify(0, "Gui", "gonvim_markdown_new_buffer") | endif
+		au GonvimAuMd BufEnter %[2]s call rpcnotify(0, "Gui", "gonvim_markdown_new_buffer")
+		au GonvimAuMd CursorMoved,CursorMovedI * if &ft == "markdown" | call rpcnotify(0, "Gui", "gonvim_markdown_cursor", line(".")) | endif
+		`, filetypes, imagePatterns)
 	}
-	if !w.uiRemoteAttached {
+	// The minimap is drawn from the buffer over RPC, so it works on remote nvim as well
+	if !editor.config.MiniMap.Disable {
 		gonvimAutoCmds = gonvimAutoCmds + `
 		aug GonvimAuMinimap | au! | aug END
-		au GonvimAuMinimap BufEnter,BufWrite * call rpcnotify(0, "Gui", "gonvim_minimap_update")
-		au GonvimAuMinimap TextChanged,TextChangedI * call rpcnotify(0, "Gui", "gonvim_minimap_sync")
+		au GonvimAuMinimap BufEnter,BufWrite,FocusGained * call rpcnotify(0, "Gui", "gonvim_minimap_update")
+		au GonvimAuMinimap TextChanged,TextChangedI,CursorHold * call rpcnotify(0, "Gui", "gonvim_minimap_sync")
+		au GonvimAuMinimap CmdlineLeave [/\?:] call timer_start(0, {-> rpcnotify(0, "Gui", "gonvim_minimap_sync")})
+		au GonvimAuMinimap CursorMoved * if [@/, v:hlsearch] != get(g:, "gonvim_minimap_search", []) | let g:gonvim_minimap_search = [@/, v:hlsearch] | call rpcnotify(0, "Gui", "gonvim_minimap_sync") | endif
+		silent! au GonvimAuMinimap DiagnosticChanged * call rpcnotify(0, "Gui", "gonvim_minimap_sync")
 		au GonvimAuMinimap ColorScheme * call rpcnotify(0, "Gui", "gonvim_colorscheme")
 		`
 	}
@@ -684,6 +712,26 @@ func (w *Workspace) initGonvim() {
 	au  GonvimAuTextChanged TextChanged,TextChangedI * call rpcnotify(0, "Gui", "gonvim_textchanged", line("$"))
 	`
 	}
+	if editor.config.ScrollBar.Visible {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuScrollBar | au! | aug END
+	au GonvimAuScrollBar BufEnter,BufWritePost,FocusGained * call rpcnotify(0, "Gui", "gonvim_scrollbar_marks", v:true)
+	au GonvimAuScrollBar TextChanged,InsertLeave,CursorHold * call rpcnotify(0, "Gui", "gonvim_scrollbar_marks", v:false)
+	au GonvimAuScrollBar CmdlineLeave [/\?:] call timer_start(0, {-> rpcnotify(0, "Gui", "gonvim_scrollbar_marks", v:false)})
+	au GonvimAuScrollBar CursorMoved * if [@/, v:hlsearch] != get(g:, "gonvim_scrollbar_search", []) | let g:gonvim_scrollbar_search = [@/, v:hlsearch] | call rpcnotify(0, "Gui", "gonvim_scrollbar_marks", v:false) | endif
+	silent! au GonvimAuScrollBar DiagnosticChanged * call rpcnotify(0, "Gui", "gonvim_scrollbar_marks", v:false)
+	`
+	}
+	// The buffer list is sent after a timer, so that deleted buffers are no longer listed
+	if editor.config.Editor.ExtTabline && editor.config.Tabline.Mode == "buffers" {
+		notifyBuffers := `call timer_start(0, {-> rpcnotify(0, "Gui", "gonvim_buffers", bufnr("%"), map(getbufinfo({"buflisted": 1}), {_, b -> [b.bufnr, b.name, b.changed, getbufvar(b.bufnr, "&readonly")]}))})`
+		gonvimAutoCmds = gonvimAutoCmds + fmt.Sprintf(`
+	aug GonvimAuBufferLine | au! | aug END
+	au GonvimAuBufferLine BufAdd,BufDelete,BufEnter,BufFilePost,BufWritePost * %[1]s
+	au GonvimAuBufferLine OptionSet readonly,buflisted %[1]s
+	silent! au GonvimAuBufferLine BufModifiedSet * %[1]s
+	`, notifyBuffers)
+	}
 	if editor.config.Editor.Clipboard {
 		gonvimAutoCmds = gonvimAutoCmds + `
 	aug GonvimAuClipboard | au! | aug END
@@ -703,18 +751,22 @@ func (w *Workspace) initGonvim() {
 	gonvimCommands := fmt.Sprintf(`
 	command! -nargs=1 GonvimResize call rpcnotify(0, "Gui", "gonvim_resize", <args>)
 	command! GonvimSidebarShow call rpcnotify(0, "Gui", "side_open")
+	command! GonvimMessageLog call rpcnotify(0, "Gui", "gonvim_message_log")
+	command! GonvimNotificationCenter call rpcnotify(0, "Gui", "gonvim_notification_center")
+	command! GonvimDoNotDisturb call rpcnotify(0, "Gui", "gonvim_do_not_disturb")
 	command! GonvimVersion echo "%s"`, editor.version)
 	if !editor.config.Markdown.Disable {
 		gonvimCommands += `
 		command! GonvimMarkdown call rpcnotify(0, "Gui", "gonvim_markdown_toggle")
+		command! -nargs=1 -complete=file GonvimMarkdownExport call rpcnotify(0, "Gui", "gonvim_markdown_export", fnamemodify(expand(<q-args>), ":p"))
 		`
 	}
-	if !w.uiRemoteAttached {
-		if !editor.config.MiniMap.Disable {
-			gonvimCommands = gonvimCommands + `
+	if !editor.config.MiniMap.Disable {
+		gonvimCommands = gonvimCommands + `
 		command! GonvimMiniMap call rpcnotify(0, "Gui", "gonvim_minimap_toggle")
 		`
-		}
+	}
+	if !w.uiRemoteAttached {
 		gonvimCommands = gonvimCommands + `
 	command! GonvimWorkspaceNew call rpcnotify(0, "Gui", "gonvim_workspace_new")
 	command! GonvimWorkspaceNext call rpcnotify(0, "Gui", "gonvim_workspace_next")
@@ -733,6 +785,8 @@ func (w *Workspace) initGonvim() {
 
 	gonvimInitNotify := `
 	call rpcnotify(0, "statusline", "bufenter", expand("%:p"), &filetype, &fileencoding, &fileformat, &ro)
+	call rpcnotify(0, "Gui", "gonvim_menu", menu_get("", "a"), &mousemodel)
+	call rpcnotify(0, "Gui", "gonvim_workspace_cwd", {"scope": "global", "cwd": getcwd()})
 	`
 	initialNotify := fmt.Sprintf(`call execute(%s)`, util.SplitVimscript(gonvimInitNotify))
 	w.nvim.Command(initialNotify)
@@ -969,6 +1023,7 @@ func (w *Workspace) handleChangeCwd(cwdinfo map[string]interface{}) {
 
 func (w *Workspace) setCwd(cwd string) {
 	w.cwd = cwd
+	w.loadMessageLog()
 	if editor.side == nil {
 		return
 	}
@@ -1206,13 +1261,20 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 			}
 			w.disableImeInNormal()
 		case "mouse_on":
+			w.isMouseEnabled = true
 		case "mouse_off":
+			w.isMouseEnabled = false
 		case "busy_start":
+			w.setBusy(true)
 		case "busy_stop":
+			w.setBusy(false)
 		case "suspend":
 		case "update_menu":
+			go w.nvim.Command(`call rpcnotify(0, "Gui", "gonvim_menu", menu_get("", "a"), &mousemodel)`)
 		case "bell":
+			w.bell(editor.config.Editor.Bell)
 		case "visual_bell":
+			w.bell("flash")
 		case "flush":
 			w.flush()
 
@@ -1263,6 +1325,7 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 			s.msgSetPos(args)
 		case "win_viewport":
 			w.windowViewport(args[0].([]interface{}))
+			w.screen.setWindowViewports(args)
 
 		// Popupmenu Events
 		case "popupmenu_show":
@@ -1346,8 +1409,17 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 				w.cmdline.functionHide()
 			}
 		case "cmdline_block_show":
+			if w.cmdline != nil {
+				w.cmdline.blockShow(args)
+			}
 		case "cmdline_block_append":
+			if w.cmdline != nil {
+				w.cmdline.blockAppend(args)
+			}
 		case "cmdline_block_hide":
+			if w.cmdline != nil {
+				w.cmdline.blockHide()
+			}
 
 		// // -- deprecated events
 		// case "wildmenu_show":
@@ -1359,12 +1431,16 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 
 		// Message/Dialog Events
 		case "msg_show":
+			w.logMessages(args)
 			w.message.msgShow(args)
 		case "msg_clear":
 			w.message.msgClear()
 		case "msg_showmode":
+			w.updateMessageStatus("mode-message", args)
 		case "msg_showcmd":
+			w.updateMessageStatus("showcmd", args)
 		case "msg_ruler":
+			w.updateMessageStatus("ruler", args)
 		case "msg_history_show":
 			w.message.msgHistoryShow(args)
 
@@ -1375,6 +1451,15 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 	}
 }
 
+// setBusy hides the cursor while neovim is busy, and spins the busy
+// component of the statusline
+func (w *Workspace) setBusy(busy bool) {
+	w.cursor.setBusy(busy)
+	if w.drawStatusline && w.statusline != nil {
+		w.statusline.busy.setBusy(busy)
+	}
+}
+
 func (w *Workspace) flush() {
 	for {
 		if len(w.viewportQue) == 0 {
@@ -1392,6 +1477,7 @@ func (w *Workspace) flush() {
 	w.screen.update()
 	w.cursor.update()
 	w.drawOtherUI()
+	w.refreshThumbnail()
 }
 
 func (w *Workspace) drawOtherUI() {
@@ -1522,6 +1608,7 @@ func (w *Workspace) updateWorkspaceColor() {
 		w.message.setColor()
 	}
 	w.screen.setColor()
+	w.menu.setColor()
 
 	// if w.drawTabline {
 	// 	if w.tabline != nil {
@@ -1760,34 +1847,13 @@ func (w *Workspace) smoothScroll(win *Window, diff int) {
 }
 
 func (w *Workspace) updateMinimap() {
-	var absMapTop int
-	var absMapBottom int
-	if w.api5 {
-		absMapTop = w.minimap.viewport[0]
-		absMapBottom = w.minimap.viewport[1]
-	} else {
-		w.minimap.nvim.Eval("line('w0')", &absMapTop)
-		w.minimap.nvim.Eval("line('w$')", &absMapBottom)
-	}
 	w.viewportMutex.RLock()
 	topLine := w.viewport[0]
 	botLine := w.viewport[1]
 	currLine := w.viewport[2]
 	w.viewportMutex.RUnlock()
-	switch {
-	case botLine >= absMapBottom:
-		go func() {
-			w.minimap.nvim.Input(`<ScrollWheelDown>`)
-			w.minimap.nvim.Command(fmt.Sprintf("call cursor(%d, %d)", currLine, 0))
-			w.minimap.nvim.Input(`zz`)
-		}()
-	case absMapTop >= topLine:
-		go func() {
-			w.minimap.nvim.Input(`<ScrollWheelUp>`)
-			w.minimap.nvim.Command(fmt.Sprintf("call cursor(%d, %d)", currLine, 0))
-			w.minimap.nvim.Input(`zz`)
-		}()
-	default:
+	if w.minimap.follow(topLine, botLine, currLine) {
+		go w.minimap.bufSync()
 	}
 }
 
@@ -1862,15 +1928,39 @@ func (w *Workspace) handleRPCGui(updates []interface{}) {
 	case "gonvim_minimap_sync":
 		if w.minimap != nil {
 			if w.minimap.visible {
-				go w.minimap.bufSync()
+				w.minimap.requestSync()
 			}
 		}
+	case "gonvim_buffers":
+		if w.tabline != nil {
+			w.tabline.updateBuffers(updates[1:])
+		}
+	case "gonvim_scrollbar_marks":
+		if w.scrollBar != nil {
+			w.scrollBar.requestMarks(updates[1].(bool))
+		}
+	case "gonvim_notify":
+		if len(updates) > 1 {
+			w.pluginNotify(updates[1])
+		}
+	case "gonvim_notification_center":
+		editor.toggleNotificationCenter()
+	case "gonvim_do_not_disturb":
+		editor.toggleDoNotDisturb()
+	case "gonvim_message_log":
+		editor.toggleMessageLog()
+	case "gonvim_menu":
+		w.menu.update(updates[1])
+		if len(updates) > 2 {
+			w.menu.setMouseModel(updates[2])
+		}
+	case "gonvim_context_menu":
+		w.menu.showContextMenu(updates[1])
 	case "gonvim_minimap_toggle":
 		go w.minimap.toggle()
 	case "gonvim_colorscheme":
 		if w.minimap != nil {
-			w.minimap.isSetColorscheme = false
-			w.minimap.setColorscheme()
+			go w.minimap.bufSync()
 		}
 
 		win, ok := w.screen.getWindow(w.cursor.gridid)
@@ -1948,6 +2038,19 @@ func (w *Workspace) handleRPCGui(updates []interface{}) {
 			w.signal.LazyDrawSignal()
 		}
 		w.markdown.toggle()
+	case "gonvim_markdown_export":
+		if editor.config.Markdown.Disable {
+			return
+		}
+		if w.markdown == nil {
+			w.signal.LazyDrawSignal()
+		}
+		go w.markdown.export(updates[1].(string))
+	case "gonvim_markdown_cursor":
+		if w.markdown == nil {
+			return
+		}
+		w.markdown.syncCursor(util.ReflectToInt(updates[1]))
 	case "gonvim_markdown_scroll_down":
 		w.markdown.scrollDown()
 	case "gonvim_markdown_scroll_up":
@@ -2307,6 +2410,10 @@ func (w *Workspace) optionSet(updates []interface{}) {
 	switch optionName {
 	case editor.config.Editor.OptionsToUseGuideWidth:
 		w.setBuffTS()
+	case "mousemodel":
+		if w.menu != nil {
+			w.menu.setMouseModel(updates[2])
+		}
 
 	}
 	w.optionsetMutex.Unlock()
@@ -2674,10 +2781,35 @@ func newWorkspaceSideItem() *WorkspaceSideItem {
 
 	sideitem.widget.ConnectMousePressEvent(sideitem.toggleContent)
 	content.ConnectItemDoubleClicked(sideitem.fileDoubleClicked)
+	labelWidget.ConnectEnterEvent(sideitem.enterEvent)
+	labelWidget.ConnectLeaveEvent(func(event *core.QEvent) {
+		editor.hideThumbnail()
+	})
 
 	return sideitem
 }
 
+// enterEvent shows the thumbnail of the workspace of the item
+func (i *WorkspaceSideItem) enterEvent(event *core.QEvent) {
+	if !editor.config.SideBar.ShowThumbnail || i.hidden {
+		return
+	}
+	for j, ws := range editor.workspaces {
+		if editor.side.items[j] != i {
+			continue
+		}
+		ws := ws
+		isActive := j == editor.active
+		pos := i.labelWidget.MapToGlobal(core.NewQPoint2(i.labelWidget.Width(), 0))
+		editor.showThumbnail(pos, func() *gui.QPixmap {
+			if isActive {
+				return ws.screen.grabThumbnail()
+			}
+			return ws.thumbnail
+		})
+	}
+}
+
 func (i *WorkspaceSideItem) fileDoubleClicked(item *widgets.QListWidgetItem) {
 	filename := item.Text()
 	path := i.cwdpath
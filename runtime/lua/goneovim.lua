@@ -0,0 +1,100 @@
+-- The goneovim module lets plugins use the features of goneovim.
+--
+--   local id = require("goneovim").notify(message, {
+--     level = "warn",             -- "error", "warn", "info", "debug" or vim.log.levels
+--     title = "Reload",
+--     timeout = 0,                -- seconds to show, 0 keeps it until closed
+--     buttons = {"Reload", "Ignore"},
+--     on_click = function(button, index, id) end,
+--     replace = id,               -- updates the notification of the id
+--   })
+--
+-- When a button is clicked, on_click is called and the User
+-- GoneovimNotifyClicked autocmd is fired with g:goneovim_notify_clicked set to
+-- {"id": id, "index": index, "button": button}. on_click is forgotten once a
+-- button is clicked, or once goneovim drops the notification.
+--
+-- override_notify() replaces vim.notify with the notifications of goneovim.
+-- The timeout of vim.notify is in milliseconds, and false keeps the
+-- notification until closed.
+
+local M = {}
+local callbacks = {}
+local last_id = 0
+
+function M.notify(message, opts)
+  opts = opts or {}
+  local id = opts.replace
+  if type(id) == "table" then
+    id = id.id
+  end
+  if type(id) ~= "number" then
+    last_id = last_id + 1
+    id = last_id
+  end
+  -- A replacement without buttons keeps the buttons of the notification
+  local buttons = opts.buttons or {}
+  if #buttons > 0 then
+    callbacks[id] = opts.on_click
+  end
+  vim.rpcnotify(0, "Gui", "gonvim_notify", {
+    id = id,
+    level = opts.level or "info",
+    title = opts.title or "",
+    timeout = opts.timeout or -1,
+    message = tostring(message),
+    buttons = buttons,
+  })
+  return id
+end
+
+function M.vim_notify(message, level, opts)
+  opts = opts or {}
+  local timeout = -1
+  if opts.timeout == false then
+    timeout = 0
+  elseif type(opts.timeout) == "number" then
+    timeout = math.max(1, math.ceil(opts.timeout / 1000))
+  end
+  local id = M.notify(message, {
+    level = level or vim.log.levels.INFO,
+    title = opts.title,
+    timeout = timeout,
+    replace = opts.replace,
+  })
+  return {id = id}
+end
+
+function M.override_notify()
+  if vim.notify ~= M.vim_notify then
+    M.original_notify = vim.notify
+    vim.notify = M.vim_notify
+  end
+end
+
+-- setup is called by goneovim when it attaches to nvim
+function M.setup(opts)
+  opts = opts or {}
+  if opts.override_notify then
+    M.override_notify()
+  end
+end
+
+-- _clicked is called by goneovim when a button of the notification is clicked
+function M._clicked(id, index, button)
+  local callback = callbacks[id]
+  callbacks[id] = nil
+  vim.g.goneovim_notify_clicked = {id = id, index = index, button = button}
+  if callback then
+    callback(button, index, id)
+  end
+  vim.cmd("doautocmd <nomodeline> User GoneovimNotifyClicked")
+end
+
+-- _dismissed is called by goneovim when the notification can no longer be
+-- clicked
+function M._dismissed(id)
+  callbacks[id] = nil
+end
+
+return M
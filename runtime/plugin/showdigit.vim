@@ -1,3 +1,7 @@
+if !get(g:, 'gonvim_popupmenu_showdigit', 0)
+  finish
+endif
+
 " map 0-9 to nth item
 let s:shiftKeys = '!@#$%^&*('
 for s:i in range(0, 9)